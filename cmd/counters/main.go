@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"github.com/efimovad/Forums.git/internal/app"
+	general_rep "github.com/efimovad/Forums.git/internal/app/general/repository"
+	"github.com/efimovad/Forums.git/internal/store"
+	"log"
+	"os"
+)
+
+// Checks forum thread/post counters and thread vote counters against the
+// actual rows and, with -repair, rewrites the ones that drifted. The schema
+// is left as it is, so the check sees the counters the server wrote; after
+// an upgrade that adds a counter column, -repair also fills it in.
+func main() {
+	databaseURL := flag.String("database", app.NewConfig().DatabaseURL, "database connection string")
+	repair := flag.Bool("repair", false, "fix drifted counters")
+	flag.Parse()
+
+	db, err := store.Open(*databaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	rep := general_rep.NewGeneralRepository(db)
+
+	drift, err := rep.CheckCounters()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, d := range drift {
+		if d.Thread != 0 {
+			log.Printf("thread %d (forum %s): %s stored %d, actual %d", d.Thread, d.Forum, d.Counter, d.Stored, d.Actual)
+			continue
+		}
+		log.Printf("forum %s: %s stored %d, actual %d", d.Forum, d.Counter, d.Stored, d.Actual)
+	}
+
+	if !*repair {
+		if len(drift) != 0 {
+			os.Exit(1)
+		}
+		log.Println("counters are consistent")
+		return
+	}
+
+	fixed, err := rep.RepairCounters()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("repaired rows:", fixed)
+}
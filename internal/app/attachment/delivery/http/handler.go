@@ -0,0 +1,226 @@
+package attachment_handler
+
+import (
+	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/attachment"
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const MULTIPART_MEMORY = 8 << 20
+
+const (
+	BODY_TOO_LARGE = "request body too large"
+	POSTS_FIRST = "posts have to be the first field of the form"
+)
+
+type Handler struct {
+	usecase			attachment.Usecase
+	sessionStore	sessions.Store
+}
+
+// NewAttachmentHandler has to be registered before the forum handler: it
+// takes over multipart requests to the post creation route and leaves JSON
+// ones to the forum handler.
+func NewAttachmentHandler(m *mux.Router, u attachment.Usecase, sessionStore sessions.Store) {
+	handler := &Handler{
+		usecase:		u,
+		sessionStore:   sessionStore,
+	}
+
+	m.HandleFunc("/api/thread/{slug_or_id}/create", handler.CreatePosts).Methods(http.MethodPost).
+		HeadersRegexp("Content-Type", "^multipart/form-data")
+	m.HandleFunc("/api/post/{id}/attachments", handler.GetPostAttachments).Methods(http.MethodGet)
+	m.HandleFunc("/api/attachment/{id}", handler.Download).Methods(http.MethodGet, http.MethodHead)
+}
+
+// CreatePosts expects the posts as JSON in the "posts" field and files in
+// fields named "attachment_<n>", where n is the index of the post in that
+// array. The posts come first: until they are read the body is limited to
+// the room for them, then to what their attachments may take.
+func (h *Handler) CreatePosts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	body := &limitedBody{ReadCloser: r.Body, limit: h.usecase.MaxUploadSize(0)}
+	r.Body = body
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, errors.Wrap(err, "AttachmentHandler.CreatePosts<-MultipartReader()"))
+		return
+	}
+
+	part, err := mr.NextPart()
+	if err == nil && part.FormName() != "posts" {
+		err = errors.New(POSTS_FIRST)
+	}
+	if err != nil {
+		h.uploadError(w, r, errors.Wrap(err, "AttachmentHandler.CreatePosts<-NextPart()"))
+		return
+	}
+
+	var list []*models.Post
+	if err := json.NewDecoder(part).Decode(&list); err != nil {
+		h.uploadError(w, r, errors.Wrap(err, "AttachmentHandler.CreatePosts<-Decode()"))
+		return
+	}
+	body.limit = h.usecase.MaxUploadSize(len(list))
+
+	form, err := mr.ReadForm(MULTIPART_MEMORY)
+	if err != nil {
+		h.uploadError(w, r, errors.Wrap(err, "AttachmentHandler.CreatePosts<-ReadForm()"))
+		return
+	}
+	defer func() {
+		if err := form.RemoveAll(); err != nil {
+			log.Println(errors.Wrap(err, "AttachmentHandler.CreatePosts<-RemoveAll()"))
+		}
+	}()
+
+	var uploads []*attachment.Upload
+	for name, files := range form.File {
+		if !strings.HasPrefix(name, "attachment_") {
+			continue
+		}
+
+		post, err := strconv.Atoi(strings.TrimPrefix(name, "attachment_"))
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		for _, header := range files {
+			file, err := header.Open()
+			if err != nil {
+				general.Error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			defer file.Close()
+
+			uploads = append(uploads, &attachment.Upload{
+				Post:		post,
+				Filename:	header.Filename,
+				Size:		header.Size,
+				Content:	file,
+			})
+		}
+	}
+
+	err = h.usecase.CreatePosts(mux.Vars(r)["slug_or_id"], list, uploads)
+	if err != nil && strings.Contains(err.Error(), attachment.TOO_LARGE) {
+		general.Error(w, r, http.StatusRequestEntityTooLarge, err)
+		return
+	} else if err != nil && strings.Contains(err.Error(), attachment.WRONG_TYPE) {
+		general.Error(w, r, http.StatusUnsupportedMediaType, err)
+		return
+	} else if err != nil && (strings.Contains(err.Error(), attachment.TOO_MANY) ||
+		strings.Contains(err.Error(), attachment.WRONG_POST)) {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	} else if err != nil && (strings.Contains(err.Error(), forum.PARENT_POST_CONFLICT) || err.Error() == forum.THREAD_MOVED) {
+		general.Error(w, r, http.StatusConflict, err)
+		return
+	} else if err != nil && strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	general.Respond(w, r, http.StatusCreated, list)
+}
+
+func (h *Handler) uploadError(w http.ResponseWriter, r *http.Request, err error) {
+	if strings.Contains(err.Error(), BODY_TOO_LARGE) {
+		general.Error(w, r, http.StatusRequestEntityTooLarge, err)
+	} else {
+		general.Error(w, r, http.StatusBadRequest, err)
+	}
+}
+
+// limitedBody fails reads past limit, like http.MaxBytesReader, but lets
+// the limit grow while the body is read. The multipart reader reads ahead,
+// so the first limit has to leave room for its buffer.
+type limitedBody struct {
+	io.ReadCloser
+	limit	int64
+	read	int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	// One byte more than is left tells a body that ends right at the limit
+	// from one that goes on.
+	left := b.limit - b.read
+	if int64(len(p)) > left+1 {
+		p = p[:left+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > left {
+		b.read = b.limit
+		return int(left), errors.New(BODY_TOO_LARGE)
+	}
+	b.read += int64(n)
+	return n, err
+}
+
+func (h *Handler) GetPostAttachments(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	list, err := h.usecase.GetPostAttachments(id)
+	if err != nil && strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+// Download serves the file itself. http.ServeContent takes care of Range
+// and conditional requests.
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	a, file, err := h.usecase.Open(id, general.CurrentUser(r))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		if err.Error() == attachment.UNAUTHORIZED {
+			general.Error(w, r, http.StatusUnauthorized, err)
+		} else if strings.Contains(err.Error(), "Can't find") {
+			general.Error(w, r, http.StatusNotFound, err)
+		} else {
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", a.MimeType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	http.ServeContent(w, r, a.Filename, a.Created, file)
+}
+
@@ -0,0 +1,134 @@
+package attachment_handler
+
+import (
+	"bytes"
+	"github.com/efimovad/Forums.git/internal/app/attachment"
+	"github.com/efimovad/Forums.git/internal/models"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// The slack has to exceed the read buffer of the multipart reader, as
+// attachment.UPLOAD_SLACK does.
+const (
+	perPost = 10000
+	slack = 5000
+)
+
+type fakeUsecase struct {
+	attachment.Usecase
+	uploads []*attachment.Upload
+}
+
+func (u *fakeUsecase) MaxUploadSize(posts int) int64 {
+	return int64(posts) * perPost + slack
+}
+
+func (u *fakeUsecase) CreatePosts(slugOrID string, posts []*models.Post, uploads []*attachment.Upload) error {
+	for _, up := range uploads {
+		if _, err := ioutil.ReadAll(up.Content); err != nil {
+			return err
+		}
+	}
+	u.uploads = uploads
+	return nil
+}
+
+type field struct {
+	name	string
+	value	string
+}
+
+func form(t *testing.T, fields ...field) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for _, f := range fields {
+		var err error
+		if strings.HasPrefix(f.name, "attachment_") {
+			part, err := mw.CreateFormFile(f.name, f.name+".txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = part.Write([]byte(f.value))
+		} else {
+			err = mw.WriteField(f.name, f.value)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body, mw.FormDataContentType()
+}
+
+func post(t *testing.T, u *fakeUsecase, fields ...field) int {
+	body, contentType := form(t, fields...)
+	r := httptest.NewRequest(http.MethodPost, "/api/thread/1/create", body)
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+
+	h := &Handler{usecase: u}
+	h.CreatePosts(w, r)
+	return w.Code
+}
+
+func TestCreatePostsLimitGrowsWithPosts(t *testing.T) {
+	file := strings.Repeat("x", 9000)
+
+	u := new(fakeUsecase)
+	code := post(t, u,
+		field{"posts", `[{"message":"a"},{"message":"b"}]`},
+		field{"attachment_0", file},
+		field{"attachment_1", file},
+	)
+	if code != http.StatusCreated {
+		t.Fatalf("two posts with a file each: got %d, want %d", code, http.StatusCreated)
+	}
+	if len(u.uploads) != 2 {
+		t.Errorf("got %d uploads, want 2", len(u.uploads))
+	}
+
+	code = post(t, new(fakeUsecase),
+		field{"posts", `[{"message":"a"}]`},
+		field{"attachment_0", file},
+		field{"attachment_0", file},
+	)
+	if code != http.StatusRequestEntityTooLarge {
+		t.Errorf("one post with two files: got %d, want %d", code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestCreatePostsLimitsPosts(t *testing.T) {
+	code := post(t, new(fakeUsecase),
+		field{"posts", `[{"message":"` + strings.Repeat("x", slack) + `"}]`},
+	)
+	if code != http.StatusRequestEntityTooLarge {
+		t.Errorf("got %d, want %d", code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestCreatePostsNeedsPostsFirst(t *testing.T) {
+	code := post(t, new(fakeUsecase),
+		field{"attachment_0", "x"},
+		field{"posts", `[{"message":"a"}]`},
+	)
+	if code != http.StatusBadRequest {
+		t.Errorf("got %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestLimitedBodyEndingAtLimit(t *testing.T) {
+	for _, size := range []int{9, 10, 11} {
+		b := &limitedBody{ReadCloser: ioutil.NopCloser(strings.NewReader(strings.Repeat("x", size))), limit: 10}
+		_, err := ioutil.ReadAll(b)
+		if tooLarge := err != nil && err.Error() == BODY_TOO_LARGE; tooLarge != (size > 10) {
+			t.Errorf("body of %d bytes with limit 10: got error %v", size, err)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package attachment
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+type Repository interface {
+	Create(a *models.Attachment) error
+	Find(id int64) (*models.Attachment, error)
+	FindByPost(post int64) ([]*models.Attachment, error)
+}
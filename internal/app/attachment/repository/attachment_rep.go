@@ -0,0 +1,64 @@
+package attachment_rep
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/attachment"
+	"github.com/efimovad/Forums.git/internal/models"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) attachment.Repository {
+	return &Repository{db}
+}
+
+func (r *Repository) Create(a *models.Attachment) error {
+	return r.db.QueryRow(
+		"INSERT INTO attachments (post, filename, mime, size, key) VALUES ($1, $2, $3, $4, $5) " +
+			"RETURNING id, created",
+		a.Post,
+		a.Filename,
+		a.MimeType,
+		a.Size,
+		a.Key,
+	).Scan(&a.ID, &a.Created)
+}
+
+func (r *Repository) Find(id int64) (*models.Attachment, error) {
+	a := new(models.Attachment)
+	err := r.db.QueryRow(
+		"SELECT id, post, filename, mime, size, key, created FROM attachments WHERE id = $1",
+		id,
+	).Scan(&a.ID, &a.Post, &a.Filename, &a.MimeType, &a.Size, &a.Key, &a.Created)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (r *Repository) FindByPost(post int64) ([]*models.Attachment, error) {
+	rows, err := r.db.Query(
+		"SELECT id, post, filename, mime, size, key, created FROM attachments WHERE post = $1 ORDER BY id",
+		post,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.Attachment, 0)
+	for rows.Next() {
+		a := new(models.Attachment)
+		if err := rows.Scan(&a.ID, &a.Post, &a.Filename, &a.MimeType, &a.Size, &a.Key, &a.Created); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, a)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
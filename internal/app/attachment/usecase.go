@@ -0,0 +1,35 @@
+package attachment
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/blob"
+	"github.com/efimovad/Forums.git/internal/models"
+	"io"
+)
+
+const (
+	NOT_FOUND = "Can't find such attachment"
+	POST_NOT_FOUND = "Can't find such post"
+	UNAUTHORIZED = "Log in to download attachments"
+	TOO_LARGE = "Attachment is too large: "
+	TOO_MANY = "Too many attachments for post: "
+	WRONG_TYPE = "Attachment type is not allowed: "
+	WRONG_POST = "Attachment refers to a missing post: "
+)
+
+const UPLOAD_SLACK = 1 << 20
+
+// Upload is a file received together with new posts. Post is the index of
+// its post in the created batch.
+type Upload struct {
+	Post		int
+	Filename	string
+	Size		int64
+	Content		io.Reader
+}
+
+type Usecase interface {
+	CreatePosts(slugOrID string, posts []*models.Post, uploads []*Upload) error
+	GetPostAttachments(post int64) ([]*models.Attachment, error)
+	MaxUploadSize(posts int) int64
+	Open(id int64, nickname string) (*models.Attachment, blob.File, error)
+}
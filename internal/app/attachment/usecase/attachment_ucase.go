@@ -0,0 +1,204 @@
+package attachment_ucase
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/efimovad/Forums.git/internal/app/attachment"
+	"github.com/efimovad/Forums.git/internal/app/blob"
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+)
+
+type AttachmentUcase struct {
+	repository	attachment.Repository
+	forumUcase	forum.Usecase
+	store		blob.BlobStore
+	maxSize		int64
+	maxCount	int
+	types		map[string]bool
+}
+
+func NewAttachmentUsecase(r attachment.Repository, fu forum.Usecase, store blob.BlobStore,
+	maxSize int64, maxCount int, types []string) attachment.Usecase {
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[t] = true
+	}
+
+	return &AttachmentUcase{
+		repository: r,
+		forumUcase: fu,
+		store:		store,
+		maxSize:	maxSize,
+		maxCount:	maxCount,
+		types:		allowed,
+	}
+}
+
+// CreatePosts stores the uploads, creates the posts and links the uploads to
+// them. Blobs are written first and removed again if the posts can't be
+// created, so nothing is linked to a half written file.
+func (u *AttachmentUcase) CreatePosts(slugOrID string, posts []*models.Post, uploads []*attachment.Upload) error {
+	perPost := make(map[int]int)
+	for _, up := range uploads {
+		if up.Post < 0 || up.Post >= len(posts) {
+			return errors.New(attachment.WRONG_POST + strconv.Itoa(up.Post))
+		}
+
+		perPost[up.Post]++
+		if perPost[up.Post] > u.maxCount {
+			return errors.New(attachment.TOO_MANY + strconv.Itoa(up.Post))
+		}
+
+		if up.Size > u.maxSize {
+			return errors.New(attachment.TOO_LARGE + up.Filename)
+		}
+	}
+
+	stored := make([]*models.Attachment, 0, len(uploads))
+	cleanup := func() {
+		for _, a := range stored {
+			if err := u.store.Delete(a.Key); err != nil {
+				log.Println(errors.Wrap(err, "store.Delete()"))
+			}
+		}
+	}
+
+	for _, up := range uploads {
+		a, err := u.put(up)
+		if err != nil {
+			cleanup()
+			return err
+		}
+		stored = append(stored, a)
+	}
+
+	if err := u.forumUcase.CreatePosts(slugOrID, posts); err != nil {
+		cleanup()
+		return err
+	}
+
+	for i, a := range stored {
+		post := posts[uploads[i].Post]
+		// Posts held by the content filters aren't stored yet and their
+		// files are dropped.
+		if post.ID == 0 {
+			if err := u.store.Delete(a.Key); err != nil {
+				log.Println(errors.Wrap(err, "store.Delete()"))
+			}
+			continue
+		}
+
+		a.Post = post.ID
+		if err := u.repository.Create(a); err != nil {
+			// The posts are published already; only the files not linked
+			// yet can go.
+			stored = stored[i:]
+			cleanup()
+			return errors.Wrap(err, "repository.Create()")
+		}
+		a.URL = downloadURL(a.ID)
+		post.Attachments = append(post.Attachments, a)
+	}
+	return nil
+}
+
+// put sniffs the content type and writes the upload to the blob store,
+// refusing anything larger than the limit even if the declared size lied.
+func (u *AttachmentUcase) put(up *attachment.Upload) (*models.Attachment, error) {
+	content := bufio.NewReaderSize(up.Content, 512)
+	head, err := content.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, errors.Wrap(err, "Peek()")
+	}
+
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !u.types[mimeType] {
+		return nil, errors.New(attachment.WRONG_TYPE + up.Filename)
+	}
+
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := u.store.Put(key, io.LimitReader(content, u.maxSize + 1))
+	if err != nil {
+		return nil, errors.Wrap(err, "store.Put()")
+	}
+
+	if size > u.maxSize {
+		_ = u.store.Delete(key)
+		return nil, errors.New(attachment.TOO_LARGE + up.Filename)
+	}
+
+	return &models.Attachment{
+		Filename:	filepath.Base(up.Filename),
+		MimeType:	mimeType,
+		Size:		size,
+		Key:		key,
+	}, nil
+}
+
+// MaxUploadSize bounds a whole upload request: the files of every post at
+// their largest plus room for the posts themselves.
+func (u *AttachmentUcase) MaxUploadSize(posts int) int64 {
+	return int64(posts) * int64(u.maxCount) * u.maxSize + attachment.UPLOAD_SLACK
+}
+
+func (u *AttachmentUcase) GetPostAttachments(post int64) ([]*models.Attachment, error) {
+	if _, err := u.forumUcase.FindPost(post); err != nil {
+		return nil, errors.New(attachment.POST_NOT_FOUND)
+	}
+
+	list, err := u.repository.FindByPost(post)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.FindByPost()")
+	}
+
+	for _, a := range list {
+		a.URL = downloadURL(a.ID)
+	}
+	return list, nil
+}
+
+// Open returns the attachment for download. nickname is the user of the
+// request's session; anonymous requests may not download.
+func (u *AttachmentUcase) Open(id int64, nickname string) (*models.Attachment, blob.File, error) {
+	if nickname == "" {
+		return nil, nil, errors.New(attachment.UNAUTHORIZED)
+	}
+
+	a, err := u.repository.Find(id)
+	if err != nil {
+		return nil, nil, errors.New(attachment.NOT_FOUND)
+	}
+
+	file, err := u.store.Open(a.Key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "store.Open()")
+	}
+
+	a.URL = downloadURL(a.ID)
+	return a, file, nil
+}
+
+func newKey() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func downloadURL(id int64) string {
+	return "/api/attachment/" + strconv.FormatInt(id, 10)
+}
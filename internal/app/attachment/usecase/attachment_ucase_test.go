@@ -0,0 +1,149 @@
+package attachment_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/attachment"
+	"github.com/efimovad/Forums.git/internal/app/blob"
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type fakeStore struct {
+	blob.BlobStore
+	blobs map[string]int64
+}
+
+func (s *fakeStore) Put(key string, r io.Reader) (int64, error) {
+	n, err := io.Copy(ioutil.Discard, r)
+	s.blobs[key] = n
+	return n, err
+}
+
+func (s *fakeStore) Delete(key string) error {
+	delete(s.blobs, key)
+	return nil
+}
+
+type fakeRepository struct {
+	attachment.Repository
+	linked []*models.Attachment
+}
+
+func (r *fakeRepository) Create(a *models.Attachment) error {
+	r.linked = append(r.linked, a)
+	a.ID = int64(len(r.linked))
+	return nil
+}
+
+type fakeForum struct {
+	forum.Usecase
+	err		error
+	held	map[int]bool
+}
+
+func (f *fakeForum) CreatePosts(slugOrID string, posts []*models.Post) error {
+	if f.err != nil {
+		return f.err
+	}
+	for i, p := range posts {
+		if !f.held[i] {
+			p.ID = int64(i + 1)
+		}
+	}
+	return nil
+}
+
+func text(post int, size int) *attachment.Upload {
+	return &attachment.Upload{
+		Post:		post,
+		Filename:	"a.txt",
+		Size:		int64(size),
+		Content:	strings.NewReader(strings.Repeat("x", size)),
+	}
+}
+
+func newUsecase(fu *fakeForum) (attachment.Usecase, *fakeStore, *fakeRepository) {
+	store := &fakeStore{blobs: make(map[string]int64)}
+	rep := new(fakeRepository)
+	return NewAttachmentUsecase(rep, fu, store, 100, 2, []string{"text/plain"}), store, rep
+}
+
+func posts(n int) []*models.Post {
+	list := make([]*models.Post, n)
+	for i := range list {
+		list[i] = &models.Post{Message: "m"}
+	}
+	return list
+}
+
+func TestCreatePostsLimits(t *testing.T) {
+	lying := text(0, 150)
+	lying.Size = 10
+
+	png := text(0, 10)
+	png.Content = strings.NewReader("\x89PNG\x0D\x0A\x1A\x0A")
+
+	tests := []struct {
+		name	string
+		uploads	[]*attachment.Upload
+		err		string
+	}{
+		{"two per post", []*attachment.Upload{text(0, 10), text(0, 10), text(1, 10), text(1, 10)}, ""},
+		{"three for one post", []*attachment.Upload{text(0, 10), text(0, 10), text(0, 10)}, attachment.TOO_MANY + "0"},
+		{"declared too large", []*attachment.Upload{text(1, 101)}, attachment.TOO_LARGE + "a.txt"},
+		{"too large despite its size", []*attachment.Upload{lying}, attachment.TOO_LARGE + "a.txt"},
+		{"missing post", []*attachment.Upload{text(2, 10)}, attachment.WRONG_POST + "2"},
+		{"type not allowed", []*attachment.Upload{png}, attachment.WRONG_TYPE + "a.txt"},
+	}
+
+	for _, tt := range tests {
+		u, store, rep := newUsecase(new(fakeForum))
+		err := u.CreatePosts("1", posts(2), tt.uploads)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			} else if len(rep.linked) != len(tt.uploads) {
+				t.Errorf("%s: linked %d of %d uploads", tt.name, len(rep.linked), len(tt.uploads))
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got %v, want %q", tt.name, err, tt.err)
+		}
+		if len(store.blobs) != 0 {
+			t.Errorf("%s: %d blobs left behind", tt.name, len(store.blobs))
+		}
+	}
+}
+
+func TestCreatePostsCleansUp(t *testing.T) {
+	u, store, rep := newUsecase(&fakeForum{err: errors.New(forum.THREAD_MOVED)})
+	if err := u.CreatePosts("1", posts(1), []*attachment.Upload{text(0, 10)}); err == nil {
+		t.Fatal("failing post creation succeeded")
+	}
+	if len(store.blobs) != 0 || len(rep.linked) != 0 {
+		t.Errorf("failed posts left %d blobs and %d attachments", len(store.blobs), len(rep.linked))
+	}
+
+	u, store, rep = newUsecase(&fakeForum{held: map[int]bool{1: true}})
+	if err := u.CreatePosts("1", posts(2), []*attachment.Upload{text(0, 10), text(1, 10)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.blobs) != 1 || len(rep.linked) != 1 || rep.linked[0].Post != 1 {
+		t.Errorf("held post: %d blobs, %d attachments", len(store.blobs), len(rep.linked))
+	}
+}
+
+func TestMaxUploadSize(t *testing.T) {
+	u, _, _ := newUsecase(new(fakeForum))
+	for posts, want := range []int64{attachment.UPLOAD_SLACK, 200 + attachment.UPLOAD_SLACK, 400 + attachment.UPLOAD_SLACK} {
+		if got := u.MaxUploadSize(posts); got != want {
+			t.Errorf("MaxUploadSize(%d) = %d, want %d", posts, got, want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package blob
+
+import "io"
+
+// File is an opened blob. Seeking lets handlers serve byte ranges.
+type File interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// BlobStore keeps binary contents under opaque keys.
+type BlobStore interface {
+	Put(key string, r io.Reader) (int64, error)
+	Open(key string) (File, error)
+	Delete(key string) error
+}
@@ -0,0 +1,126 @@
+package bookmark_handler
+
+import (
+	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/bookmark"
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Handler struct {
+	usecase			bookmark.Usecase
+	sessionStore	sessions.Store
+}
+
+func NewBookmarkHandler(m *mux.Router, u bookmark.Usecase, sessionStore sessions.Store) {
+	handler := &Handler{
+		usecase:		u,
+		sessionStore:   sessionStore,
+	}
+
+	m.HandleFunc("/api/user/{nickname}/bookmarks", handler.GetBookmarks).Methods(http.MethodGet)
+	m.HandleFunc("/api/user/{nickname}/bookmarks", handler.AddBookmark).Methods(http.MethodPost)
+	m.HandleFunc("/api/user/{nickname}/bookmarks/{id}", handler.RemoveBookmark).Methods(http.MethodDelete)
+}
+
+func (h *Handler) AddBookmark(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "BookmarkHandler.AddBookmark<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	b := new(models.Bookmark)
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		err = errors.Wrapf(err, "BookmarkHandler.AddBookmark<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.Add(mux.Vars(r)["nickname"], general.CurrentUser(r), b)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusCreated, res)
+}
+
+func (h *Handler) RemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.usecase.Remove(vars["nickname"], general.CurrentUser(r), id); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := new(models.ListParameters)
+	str := r.URL.Query().Get("limit")
+	if str != "" {
+		limit, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Limit = limit
+	}
+
+	str = r.URL.Query().Get("desc")
+	if str != "" {
+		desc, err := strconv.ParseBool(str)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Desc = desc
+	}
+
+	params.Since = r.URL.Query().Get("since")
+	if params.Since != "" {
+		if _, err := strconv.ParseInt(params.Since, 10, 64); err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	list, err := h.usecase.List(mux.Vars(r)["nickname"], general.CurrentUser(r), params)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, err error) {
+	if err.Error() == bookmark.UNAUTHORIZED {
+		general.Error(w, r, http.StatusUnauthorized, err)
+	} else if err.Error() == bookmark.FORBIDDEN {
+		general.Error(w, r, http.StatusForbidden, err)
+	} else if strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+	} else if err.Error() == bookmark.WRONG_INPUT {
+		general.Error(w, r, http.StatusBadRequest, err)
+	} else {
+		general.Error(w, r, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,9 @@
+package bookmark
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+type Repository interface {
+	Create(b *models.Bookmark) error
+	Delete(nickname string, id int64) (bool, error)
+	List(nickname string, params *models.ListParameters) ([]*models.Bookmark, error)
+}
@@ -0,0 +1,91 @@
+package bookmark_rep
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/bookmark"
+	"github.com/efimovad/Forums.git/internal/models"
+	"strconv"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewBookmarkRepository(db *sql.DB) bookmark.Repository {
+	return &Repository{db}
+}
+
+// Create stores the bookmark; bookmarking the same item again updates its note.
+func (r *Repository) Create(b *models.Bookmark) error {
+	if b.PostID != 0 {
+		return r.db.QueryRow(
+			"INSERT INTO bookmarks (nickname, post, note) VALUES ($1, $2, $3) " +
+				"ON CONFLICT (LOWER(nickname), post) WHERE post IS NOT NULL " +
+				"DO UPDATE SET note = EXCLUDED.note RETURNING id, created",
+			b.Nickname,
+			b.PostID,
+			b.Note,
+		).Scan(&b.ID, &b.Created)
+	}
+
+	return r.db.QueryRow(
+		"INSERT INTO bookmarks (nickname, thread, note) VALUES ($1, $2, $3) " +
+			"ON CONFLICT (LOWER(nickname), thread) WHERE thread IS NOT NULL " +
+			"DO UPDATE SET note = EXCLUDED.note RETURNING id, created",
+		b.Nickname,
+		b.ThreadID,
+		b.Note,
+	).Scan(&b.ID, &b.Created)
+}
+
+func (r *Repository) Delete(nickname string, id int64) (bool, error) {
+	res, err := r.db.Exec("DELETE FROM bookmarks WHERE id = $1 AND LOWER(nickname) = LOWER($2)", id, nickname)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *Repository) List(nickname string, params *models.ListParameters) ([]*models.Bookmark, error) {
+	var since int64
+	if params.Since != "" {
+		var err error
+		if since, err = strconv.ParseInt(params.Since, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.Query(
+		`SELECT id, nickname, COALESCE(post, 0), COALESCE(thread, 0), note, created
+				FROM bookmarks
+				WHERE LOWER(nickname) = LOWER($1) AND
+					($2 = 0 OR (NOT $3 AND id > $2) OR ($3 AND id < $2))
+				ORDER BY
+					CASE WHEN $3 THEN id END DESC,
+					CASE WHEN NOT $3 THEN id END ASC
+				LIMIT CASE WHEN $4 > 0 THEN $4 END;`,
+		nickname, since, params.Desc, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.Bookmark, 0)
+	for rows.Next() {
+		b := new(models.Bookmark)
+		if err := rows.Scan(&b.ID, &b.Nickname, &b.PostID, &b.ThreadID, &b.Note, &b.Created); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, b)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,22 @@
+package bookmark
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+const (
+	NOT_FOUND = "Can't find such bookmark"
+	USER_NOT_FOUND = "Can't find user by nickname: "
+	POST_NOT_FOUND = "Can't find such post"
+	THREAD_NOT_FOUND = "Can't find such thread"
+	WRONG_INPUT = "Bookmark needs exactly one of post_id and thread_id"
+	UNAUTHORIZED = "Log in to keep bookmarks"
+	FORBIDDEN = "Bookmarks can only be changed by their owner"
+)
+
+// MAX_LIMIT caps a page of bookmarks, also when no limit is given.
+const MAX_LIMIT = 100
+
+type Usecase interface {
+	Add(nickname string, caller string, b *models.Bookmark) (*models.Bookmark, error)
+	Remove(nickname string, caller string, id int64) error
+	List(nickname string, caller string, params *models.ListParameters) ([]*models.Bookmark, error)
+}
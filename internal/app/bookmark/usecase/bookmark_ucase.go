@@ -0,0 +1,132 @@
+package bookmark_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/bookmark"
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+type BookmarkUcase struct {
+	repository	bookmark.Repository
+	forumRep	forum.Repository
+	userRep		user.Repository
+}
+
+func NewBookmarkUsecase(r bookmark.Repository, fr forum.Repository, ur user.Repository) bookmark.Usecase {
+	return &BookmarkUcase{
+		repository: r,
+		forumRep:	fr,
+		userRep:	ur,
+	}
+}
+
+func (u *BookmarkUcase) Add(nickname string, caller string, b *models.Bookmark) (*models.Bookmark, error) {
+	if (b.PostID == 0) == (b.ThreadID == 0) {
+		return nil, errors.New(bookmark.WRONG_INPUT)
+	}
+
+	us, err := u.owner(nickname, caller)
+	if err != nil {
+		return nil, err
+	}
+	b.Nickname = us.Nickname
+
+	if b.PostID != 0 {
+		if b.Post, err = u.forumRep.FindPost(b.PostID); err != nil {
+			return nil, errors.New(bookmark.POST_NOT_FOUND)
+		}
+	} else {
+		if b.Thread, err = u.forumRep.FindThread(b.ThreadID); err != nil {
+			return nil, errors.New(bookmark.THREAD_NOT_FOUND)
+		}
+	}
+
+	if err := u.repository.Create(b); err != nil {
+		return nil, errors.Wrap(err, "repository.Create()")
+	}
+	return b, nil
+}
+
+func (u *BookmarkUcase) Remove(nickname string, caller string, id int64) error {
+	us, err := u.owner(nickname, caller)
+	if err != nil {
+		return err
+	}
+
+	removed, err := u.repository.Delete(us.Nickname, id)
+	if err != nil {
+		return errors.Wrap(err, "repository.Delete()")
+	}
+	if !removed {
+		return errors.New(bookmark.NOT_FOUND)
+	}
+	return nil
+}
+
+// List hydrates bookmarks with the current state of their post or thread,
+// loading all posts and all threads of a page at once. Bookmarks of removed
+// items are kept and marked as deleted.
+func (u *BookmarkUcase) List(nickname string, caller string, params *models.ListParameters) ([]*models.Bookmark, error) {
+	us, err := u.owner(nickname, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Limit <= 0 || params.Limit > bookmark.MAX_LIMIT {
+		params.Limit = bookmark.MAX_LIMIT
+	}
+
+	list, err := u.repository.List(us.Nickname, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.List()")
+	}
+
+	var postIDs, threadIDs []int64
+	for _, b := range list {
+		if b.PostID != 0 {
+			postIDs = append(postIDs, b.PostID)
+		} else {
+			threadIDs = append(threadIDs, b.ThreadID)
+		}
+	}
+
+	posts, err := u.forumRep.FindPosts(postIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "forumRep.FindPosts()")
+	}
+
+	threads, err := u.forumRep.FindThreads(threadIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "forumRep.FindThreads()")
+	}
+
+	for _, b := range list {
+		if b.PostID != 0 {
+			b.Post = posts[b.PostID]
+			b.Deleted = b.Post == nil
+		} else {
+			b.Thread = threads[b.ThreadID]
+			b.Deleted = b.Thread == nil
+		}
+	}
+	return list, nil
+}
+
+func (u *BookmarkUcase) owner(nickname string, caller string) (*models.User, error) {
+	if caller == "" {
+		return nil, errors.New(bookmark.UNAUTHORIZED)
+	}
+
+	us, err := u.userRep.FindByName(nickname)
+	if err != nil {
+		return nil, errors.New(bookmark.USER_NOT_FOUND + nickname)
+	}
+
+	if !strings.EqualFold(us.Nickname, caller) {
+		return nil, errors.New(bookmark.FORBIDDEN)
+	}
+	return us, nil
+}
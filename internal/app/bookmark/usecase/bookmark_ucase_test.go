@@ -0,0 +1,96 @@
+package bookmark_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/bookmark"
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"strings"
+	"testing"
+)
+
+type fakeRepository struct {
+	bookmark.Repository
+	created	[]*models.Bookmark
+	deleted	[]string
+}
+
+func (r *fakeRepository) Create(b *models.Bookmark) error {
+	r.created = append(r.created, b)
+	return nil
+}
+
+func (r *fakeRepository) Delete(nickname string, id int64) (bool, error) {
+	r.deleted = append(r.deleted, nickname)
+	return true, nil
+}
+
+func (r *fakeRepository) List(nickname string, params *models.ListParameters) ([]*models.Bookmark, error) {
+	return nil, nil
+}
+
+type fakeForum struct {
+	forum.Repository
+}
+
+func (f fakeForum) FindPost(id int64) (*models.Post, error) {
+	return &models.Post{ID: id}, nil
+}
+
+func (f fakeForum) FindPosts(ids []int64) (map[int64]*models.Post, error) {
+	return nil, nil
+}
+
+func (f fakeForum) FindThreads(ids []int64) (map[int64]*models.Thread, error) {
+	return nil, nil
+}
+
+type fakeUsers struct {
+	user.Repository
+}
+
+func (u fakeUsers) FindByName(nickname string) (*models.User, error) {
+	if !strings.EqualFold(nickname, "owner") {
+		return nil, errors.New("no user")
+	}
+	return &models.User{Nickname: "Owner"}, nil
+}
+
+func TestOwner(t *testing.T) {
+	tests := []struct {
+		name	string
+		caller	string
+		err		string
+	}{
+		{"owner", "owner", ""},
+		{"owner in other case", "OWNER", ""},
+		{"anonymous", "", bookmark.UNAUTHORIZED},
+		{"someone else", "other", bookmark.FORBIDDEN},
+	}
+
+	for _, tt := range tests {
+		rep := new(fakeRepository)
+		u := NewBookmarkUsecase(rep, fakeForum{}, fakeUsers{})
+
+		errs := []error{}
+		_, err := u.Add("Owner", tt.caller, &models.Bookmark{PostID: 1})
+		errs = append(errs, err)
+		errs = append(errs, u.Remove("Owner", tt.caller, 1))
+		_, err = u.List("Owner", tt.caller, new(models.ListParameters))
+		errs = append(errs, err)
+
+		for i, err := range errs {
+			if (tt.err == "" && err != nil) || (tt.err != "" && (err == nil || err.Error() != tt.err)) {
+				t.Errorf("%s: call %d got %v, want %q", tt.name, i, err, tt.err)
+			}
+		}
+
+		if tt.err != "" && (len(rep.created) != 0 || len(rep.deleted) != 0) {
+			t.Errorf("%s: bookmarks were changed", tt.name)
+		}
+		if tt.err == "" && (rep.created[0].Nickname != "Owner" || rep.deleted[0] != "Owner") {
+			t.Errorf("%s: stored under %q and %q, want the user's nickname", tt.name, rep.created[0].Nickname, rep.deleted[0])
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/poll"
+	"github.com/efimovad/Forums.git/internal/app/reaction"
+	"github.com/efimovad/Forums.git/internal/models"
+	"testing"
+	"time"
+)
+
+type fakeForumRepository struct {
+	forum.Repository
+	thread	models.Thread
+	loads	int
+}
+
+func (r *fakeForumRepository) FindThread(id int64) (*models.Thread, error) {
+	r.loads++
+	t := r.thread
+	return &t, nil
+}
+
+func (r *fakeForumRepository) FindThreadBySlug(slug string) (*models.Thread, error) {
+	return r.FindThread(r.thread.ID)
+}
+
+type fakeReactionRepository struct {
+	reaction.Repository
+}
+
+func (r fakeReactionRepository) Add(item *models.Reaction) error {
+	return nil
+}
+
+func (r fakeReactionRepository) Remove(item *models.Reaction) (bool, error) {
+	return true, nil
+}
+
+type fakePollRepository struct {
+	poll.Repository
+}
+
+func (r fakePollRepository) Vote(p *models.Poll, nickname string, options []int64) error {
+	return nil
+}
+
+func TestThreadInvalidation(t *testing.T) {
+	lru := NewLRU(100, time.Minute)
+	base := &fakeForumRepository{thread: models.Thread{ID: 7, Slug: "seven", Version: 1}}
+	forumRep := NewForumRepository(base, lru)
+
+	writes := []struct {
+		name	string
+		write	func()
+	}{
+		{"thread reaction added", func() {
+			_ = NewReactionRepository(fakeReactionRepository{}, lru).Add(&models.Reaction{Thread: 7})
+		}},
+		{"thread reaction removed", func() {
+			_, _ = NewReactionRepository(fakeReactionRepository{}, lru).Remove(&models.Reaction{Thread: 7})
+		}},
+		{"poll vote", func() {
+			_ = NewPollRepository(fakePollRepository{}, lru).Vote(&models.Poll{Thread: 7}, "a", []int64{1})
+		}},
+	}
+
+	for _, w := range writes {
+		if _, err := forumRep.FindThreadBySlug("seven"); err != nil {
+			t.Fatal(err)
+		}
+		loads := base.loads
+
+		base.thread.Version++
+		w.write()
+
+		got, err := forumRep.FindThreadBySlug("SEVEN")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if base.loads != loads+1 || got.Version != base.thread.Version {
+			t.Errorf("%s: got version %d from the cache, want %d", w.name, got.Version, base.thread.Version)
+		}
+	}
+}
+
+func TestPostReactionKeepsThread(t *testing.T) {
+	lru := NewLRU(100, time.Minute)
+	base := &fakeForumRepository{thread: models.Thread{ID: 7}}
+	forumRep := NewForumRepository(base, lru)
+
+	if _, err := forumRep.FindThread(7); err != nil {
+		t.Fatal(err)
+	}
+	_ = NewReactionRepository(fakeReactionRepository{}, lru).Add(&models.Reaction{Post: 3})
+	if _, err := forumRep.FindThread(7); err != nil {
+		t.Fatal(err)
+	}
+	if base.loads != 1 {
+		t.Errorf("thread was loaded %d times, want once", base.loads)
+	}
+}
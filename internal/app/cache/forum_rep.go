@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/models"
+)
+
+// ForumRepository caches forum, thread and user lookups of the wrapped
+// repository. Methods that are not overridden go straight to it.
+type ForumRepository struct {
+	forum.Repository
+	cache *LRU
+}
+
+func NewForumRepository(r forum.Repository, c *LRU) forum.Repository {
+	return &ForumRepository{
+		Repository: r,
+		cache:      c,
+	}
+}
+
+func (r *ForumRepository) FindBySlug(slug string) (*models.Forum, error) {
+	if v, ok := r.cache.Get(forumKey(slug)); ok {
+		f := *v.(*models.Forum)
+		return &f, nil
+	}
+
+	f, err := r.Repository.FindBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keyed by the canonical slug, so lookups through an old alias are not
+	// cached under a key that a later rename could leave stale.
+	cached := *f
+	r.cache.Set(forumKey(f.Slug), &cached)
+	return f, nil
+}
+
+// RenameForum purges the whole cache: every cached thread of the forum
+// carries the old slug.
+func (r *ForumRepository) RenameForum(f *models.Forum, slug string) error {
+	err := r.Repository.RenameForum(f, slug)
+	r.cache.Purge()
+	return err
+}
+
+func (r *ForumRepository) CreateThread(thread *models.Thread) error {
+	if err := r.Repository.CreateThread(thread); err != nil {
+		return err
+	}
+	r.cache.Delete(forumKey(thread.Forum))
+	return nil
+}
+
+func (r *ForumRepository) FindThread(id int64) (*models.Thread, error) {
+	if v, ok := r.cache.Get(threadKey(id)); ok {
+		t := *v.(*models.Thread)
+		return &t, nil
+	}
+
+	t, err := r.Repository.FindThread(id)
+	if err != nil {
+		return nil, err
+	}
+	r.setThread(t)
+	return t, nil
+}
+
+// FindThreadBySlug caches only the slug's thread ID, so writes elsewhere
+// have a single entry per thread to drop.
+func (r *ForumRepository) FindThreadBySlug(slug string) (*models.Thread, error) {
+	if v, ok := r.cache.Get(threadSlugKey(slug)); ok {
+		return r.FindThread(v.(int64))
+	}
+
+	t, err := r.Repository.FindThreadBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	r.setThread(t)
+	return t, nil
+}
+
+// UpdateThread drops the cached thread even when the update fails, so a
+// version conflict caused by a stale entry is not repeated.
+func (r *ForumRepository) UpdateThread(thread *models.Thread) error {
+	err := r.Repository.UpdateThread(thread)
+	r.dropThread(thread)
+	return err
+}
+
+func (r *ForumRepository) RenameThread(thread *models.Thread, slug string) error {
+	old := *thread
+	err := r.Repository.RenameThread(thread, slug)
+	r.dropThread(&old)
+	r.dropThread(thread)
+	return err
+}
+
+func (r *ForumRepository) SetParent(f *models.Forum, parent *models.Forum) error {
+	err := r.Repository.SetParent(f, parent)
+	r.cache.Delete(forumKey(f.Slug))
+	return err
+}
+
+func (r *ForumRepository) MoveThread(thread *models.Thread, to *models.Forum, moderator string) (*models.Thread, error) {
+	from := thread.Forum
+	stub, err := r.Repository.MoveThread(thread, to, moderator)
+	r.cache.Delete(forumKey(from), forumKey(to.Slug))
+	r.dropThread(thread)
+	return stub, err
+}
+
+func (r *ForumRepository) MergeThreads(from *models.Thread, into *models.Thread, moderator string) error {
+	err := r.Repository.MergeThreads(from, into, moderator)
+	r.cache.Delete(forumKey(from.Forum), forumKey(into.Forum))
+	r.dropThread(from)
+	r.dropThread(into)
+	return err
+}
+
+func (r *ForumRepository) SplitThread(post *models.Post, from *models.Thread, thread *models.Thread, moderator string) error {
+	err := r.Repository.SplitThread(post, from, thread, moderator)
+	r.cache.Delete(forumKey(from.Forum))
+	r.dropThread(from)
+	return err
+}
+
+func (r *ForumRepository) CreatePosts(posts []*models.Post, thread *models.Thread) error {
+	if err := r.Repository.CreatePosts(posts, thread); err != nil {
+		return err
+	}
+	r.cache.Delete(forumKey(thread.Forum))
+	r.dropThread(thread)
+	return nil
+}
+
+func (r *ForumRepository) CreateVote(vote *models.Vote, thread *models.Thread) (int64, error) {
+	votes, err := r.Repository.CreateVote(vote, thread)
+	if err != nil {
+		return 0, err
+	}
+	r.dropThread(thread)
+	return votes, nil
+}
+
+func (r *ForumRepository) DeleteVote(nickname string, thread *models.Thread) error {
+	if err := r.Repository.DeleteVote(nickname, thread); err != nil {
+		return err
+	}
+	r.dropThread(thread)
+	return nil
+}
+
+func (r *ForumRepository) FindUser(nickname string) (*models.User, error) {
+	if v, ok := r.cache.Get(userKey(nickname)); ok {
+		u := *v.(*models.User)
+		return &u, nil
+	}
+
+	u, err := r.Repository.FindUser(nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := *u
+	r.cache.Set(userKey(nickname), &cached)
+	return u, nil
+}
+
+func (r *ForumRepository) setThread(t *models.Thread) {
+	cached := *t
+	r.cache.Set(threadKey(t.ID), &cached)
+	if t.Slug != "" {
+		r.cache.Set(threadSlugKey(t.Slug), t.ID)
+	}
+}
+
+func (r *ForumRepository) dropThread(t *models.Thread) {
+	r.cache.Delete(threadKey(t.ID))
+	if t.Slug != "" {
+		r.cache.Delete(threadSlugKey(t.Slug))
+	}
+}
@@ -0,0 +1,29 @@
+package cache
+
+import "github.com/efimovad/Forums.git/internal/app/general"
+
+// GeneralRepository purges the cache when the database is cleared or its
+// counters are rewritten.
+type GeneralRepository struct {
+	general.Repository
+	cache *LRU
+}
+
+func NewGeneralRepository(r general.Repository, c *LRU) general.Repository {
+	return &GeneralRepository{
+		Repository: r,
+		cache:      c,
+	}
+}
+
+func (r *GeneralRepository) DropAll() error {
+	err := r.Repository.DropAll()
+	r.cache.Purge()
+	return err
+}
+
+func (r *GeneralRepository) RepairCounters() (int64, error) {
+	fixed, err := r.Repository.RepairCounters()
+	r.cache.Purge()
+	return fixed, err
+}
@@ -0,0 +1,14 @@
+package cache
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+func NewCacheHandler(m *mux.Router, c *LRU) {
+	m.HandleFunc("/api/service/cache", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		general.Respond(w, r, http.StatusOK, c.Stats())
+	}).Methods(http.MethodGet)
+}
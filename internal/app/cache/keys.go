@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+)
+
+func forumKey(slug string) string {
+	return "forum:" + strings.ToLower(slug)
+}
+
+func threadKey(id int64) string {
+	return "thread:" + strconv.FormatInt(id, 10)
+}
+
+func threadSlugKey(slug string) string {
+	return "thread-slug:" + strings.ToLower(slug)
+}
+
+func userKey(nickname string) string {
+	return "user:" + strings.ToLower(nickname)
+}
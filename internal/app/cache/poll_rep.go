@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/poll"
+	"github.com/efimovad/Forums.git/internal/models"
+)
+
+// PollRepository drops the cached thread of a poll on every vote, which
+// touches the thread's modified time.
+type PollRepository struct {
+	poll.Repository
+	cache *LRU
+}
+
+func NewPollRepository(r poll.Repository, c *LRU) poll.Repository {
+	return &PollRepository{
+		Repository: r,
+		cache:      c,
+	}
+}
+
+func (r *PollRepository) Vote(p *models.Poll, nickname string, options []int64) error {
+	err := r.Repository.Vote(p, nickname, options)
+	r.cache.Delete(threadKey(p.Thread))
+	return err
+}
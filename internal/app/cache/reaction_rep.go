@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/reaction"
+	"github.com/efimovad/Forums.git/internal/models"
+)
+
+// ReactionRepository drops cached threads whose reactions change, since
+// that moves their modified time and with it the ETag.
+type ReactionRepository struct {
+	reaction.Repository
+	cache *LRU
+}
+
+func NewReactionRepository(r reaction.Repository, c *LRU) reaction.Repository {
+	return &ReactionRepository{
+		Repository: r,
+		cache:      c,
+	}
+}
+
+func (r *ReactionRepository) Add(item *models.Reaction) error {
+	err := r.Repository.Add(item)
+	if item.Thread != 0 {
+		r.cache.Delete(threadKey(item.Thread))
+	}
+	return err
+}
+
+func (r *ReactionRepository) Remove(item *models.Reaction) (bool, error) {
+	removed, err := r.Repository.Remove(item)
+	if item.Thread != 0 {
+		r.cache.Delete(threadKey(item.Thread))
+	}
+	return removed, err
+}
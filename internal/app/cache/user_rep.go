@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+)
+
+// UserRepository shares its cache with ForumRepository, so editing a
+// profile also invalidates users resolved through the forum repository.
+type UserRepository struct {
+	user.Repository
+	cache *LRU
+}
+
+func NewUserRepository(r user.Repository, c *LRU) user.Repository {
+	return &UserRepository{
+		Repository: r,
+		cache:      c,
+	}
+}
+
+func (r *UserRepository) FindByName(nickname string) (*models.User, error) {
+	if v, ok := r.cache.Get(userKey(nickname)); ok {
+		u := *v.(*models.User)
+		return &u, nil
+	}
+
+	u, err := r.Repository.FindByName(nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := *u
+	r.cache.Set(userKey(nickname), &cached)
+	return u, nil
+}
+
+func (r *UserRepository) Edit(u *models.User) error {
+	if err := r.Repository.Edit(u); err != nil {
+		return err
+	}
+	r.cache.Delete(userKey(u.Nickname))
+	return nil
+}
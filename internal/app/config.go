@@ -1,5 +1,11 @@
 package app
 
+import (
+	"github.com/efimovad/Forums.git/internal/app/ratelimit"
+	"github.com/efimovad/Forums.git/internal/models"
+	"time"
+)
+
 type Config struct {
 	BindAddr    string
 	LogLevel    string
@@ -7,6 +13,26 @@ type Config struct {
 	SessionKey  string
 	TokenSecret string
 	ClientUrl	string
+	RateLimit	ratelimit.Config
+	RateIdle	time.Duration
+	CacheSize	int
+	CacheTTL	time.Duration
+	RenderCacheSize	int
+	Reactions	[]string
+	Moderators	[]string
+	Filters		models.FilterSettings
+	AttachmentDir	string
+	AttachmentMaxSize	int64
+	AttachmentMaxCount	int
+	AttachmentTypes	[]string
+	DigestInterval	time.Duration
+	ScheduleInterval	time.Duration
+	Mailer		string
+	MailLog		string
+	MailFrom	string
+	SMTPAddr	string
+	SMTPUser	string
+	SMTPPassword	string
 }
 
 func NewConfig() *Config {
@@ -16,5 +42,34 @@ func NewConfig() *Config {
 		SessionKey:		"jdfhdfdj",
 		DatabaseURL:	"dbname=docker sslmode=disable port=5432 password=docker user=docker",
 		TokenSecret:	"golangsecpark",
+		RateLimit:		ratelimit.Config{
+			Read:	ratelimit.Limit{Rate: 100, Burst: 200},
+			Thread:	ratelimit.Limit{Rate: 0.2, Burst: 5},
+			Post:	ratelimit.Limit{Rate: 2, Burst: 20},
+			Vote:	ratelimit.Limit{Rate: 5, Burst: 20},
+		},
+		RateIdle:		10 * time.Minute,
+		CacheSize:		10000,
+		CacheTTL:		30 * time.Second,
+		RenderCacheSize:	10000,
+		Reactions:		[]string{"+1", "-1", "heart", "laugh", "tada", "eyes"},
+		// Filters are off until a forum sets actions for them; the limits
+		// apply once an action is set.
+		Filters:		models.FilterSettings{
+			MaxLinks:			5,
+			DuplicateWindow:	600,
+			NewAccountAge:		24 * 60 * 60,
+			NewAccountPosts:	5,
+		},
+		AttachmentDir:	"attachments",
+		AttachmentMaxSize:	10 << 20,
+		AttachmentMaxCount:	5,
+		AttachmentTypes:	[]string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain"},
+		DigestInterval:	10 * time.Minute,
+		ScheduleInterval:	30 * time.Second,
+		Mailer:			"log",
+		MailLog:		"digest.log",
+		MailFrom:		"forum@localhost",
+		SMTPAddr:		"localhost:25",
 	}
 }
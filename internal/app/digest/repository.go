@@ -0,0 +1,13 @@
+package digest
+
+import (
+	"github.com/efimovad/Forums.git/internal/models"
+	"time"
+)
+
+type Repository interface {
+	DueUsers(now time.Time) ([]*models.User, error)
+	UnreadNotifications(nickname string, since time.Time) ([]*models.Notification, error)
+	SubscribedActivity(nickname string, since time.Time) ([]*models.DigestThread, error)
+	MarkSent(nickname string, at time.Time) error
+}
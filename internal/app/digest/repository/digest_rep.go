@@ -0,0 +1,106 @@
+package digest_rep
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/digest"
+	"github.com/efimovad/Forums.git/internal/models"
+	"time"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewDigestRepository(db *sql.DB) digest.Repository {
+	return &Repository{db}
+}
+
+func (r *Repository) DueUsers(now time.Time) ([]*models.User, error) {
+	rows, err := r.db.Query(
+		`SELECT id, email, nickname, digest, COALESCE(digest_sent, 'epoch'::timestamptz)
+				FROM users
+				WHERE (digest = 'daily' AND (digest_sent IS NULL OR digest_sent <= $1 - interval '1 day')) OR
+					(digest = 'weekly' AND (digest_sent IS NULL OR digest_sent <= $1 - interval '7 days'))`,
+		now)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*models.User
+	for rows.Next() {
+		u := new(models.User)
+		if err := rows.Scan(&u.ID, &u.Email, &u.Nickname, &u.Digest, &u.DigestSent); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *Repository) UnreadNotifications(nickname string, since time.Time) ([]*models.Notification, error) {
+	rows, err := r.db.Query(
+		`SELECT id, nickname, kind, actor, thread, post, created, is_read
+				FROM notifications
+				WHERE LOWER(nickname) = LOWER($1) AND NOT is_read AND created > $2
+				ORDER BY id`,
+		nickname, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*models.Notification
+	for rows.Next() {
+		n := new(models.Notification)
+		if err := rows.Scan(&n.ID, &n.Nickname, &n.Kind, &n.Actor, &n.Thread, &n.Post, &n.Created, &n.Read); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, n)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SubscribedActivity counts posts of other users in the subscribed threads.
+func (r *Repository) SubscribedActivity(nickname string, since time.Time) ([]*models.DigestThread, error) {
+	rows, err := r.db.Query(
+		`SELECT t.id, t.forum, t.title, COUNT(p.id)
+				FROM subscriptions s
+				JOIN threads t ON t.id = s.thread
+				JOIN posts p ON p.thread = t.id
+				WHERE LOWER(s.nickname) = LOWER($1) AND p.created > $2 AND LOWER(p.author) <> LOWER($1)
+				GROUP BY t.id
+				ORDER BY COUNT(p.id) DESC, t.id`,
+		nickname, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*models.DigestThread
+	for rows.Next() {
+		item := new(models.DigestThread)
+		if err := rows.Scan(&item.Thread, &item.Forum, &item.Title, &item.Posts); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, item)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) MarkSent(nickname string, at time.Time) error {
+	_, err := r.db.Exec("UPDATE users SET digest_sent = $1 WHERE LOWER(nickname) = LOWER($2)", at, nickname)
+	return err
+}
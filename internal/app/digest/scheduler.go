@@ -0,0 +1,34 @@
+package digest
+
+import (
+	"log"
+	"time"
+)
+
+// Start runs SendDue every interval until the returned stop function is called.
+func Start(u Usecase, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				n, err := u.SendDue(now)
+				if err != nil {
+					log.Println("digest:", err)
+				}
+				if n > 0 {
+					log.Println("digest: sent", n)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
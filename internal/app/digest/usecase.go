@@ -0,0 +1,9 @@
+package digest
+
+import "time"
+
+type Usecase interface {
+	// SendDue mails a digest to every user whose digest period has passed
+	// and returns the number of digests sent.
+	SendDue(now time.Time) (int, error)
+}
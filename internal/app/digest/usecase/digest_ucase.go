@@ -0,0 +1,108 @@
+package digest_ucase
+
+import (
+	"fmt"
+	"github.com/efimovad/Forums.git/internal/app/digest"
+	"github.com/efimovad/Forums.git/internal/app/mailer"
+	"github.com/efimovad/Forums.git/internal/app/notification"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"log"
+	"strings"
+	"time"
+)
+
+type DigestUcase struct {
+	repository	digest.Repository
+	mailer		mailer.Mailer
+}
+
+func NewDigestUsecase(r digest.Repository, m mailer.Mailer) digest.Usecase {
+	return &DigestUcase{
+		repository: r,
+		mailer:		m,
+	}
+}
+
+func (u *DigestUcase) SendDue(now time.Time) (int, error) {
+	users, err := u.repository.DueUsers(now)
+	if err != nil {
+		return 0, errors.Wrap(err, "repository.DueUsers()")
+	}
+
+	sent := 0
+	for _, us := range users {
+		since := us.DigestSent
+		if since.Before(now.Add(-period(us.Digest))) {
+			since = now.Add(-period(us.Digest))
+		}
+
+		notifications, err := u.repository.UnreadNotifications(us.Nickname, since)
+		if err != nil {
+			return sent, errors.Wrap(err, "repository.UnreadNotifications()")
+		}
+
+		threads, err := u.repository.SubscribedActivity(us.Nickname, since)
+		if err != nil {
+			return sent, errors.Wrap(err, "repository.SubscribedActivity()")
+		}
+
+		if len(notifications) != 0 || len(threads) != 0 {
+			msg := &mailer.Message{
+				To:			us.Email,
+				Subject:	"Forum digest for " + us.Nickname,
+				Body:		body(notifications, threads),
+			}
+			// One broken address must not block the digests of everybody else.
+			if err := u.mailer.Send(msg); err != nil {
+				log.Println(errors.Wrap(err, "mailer.Send() to "+us.Nickname))
+				continue
+			}
+			sent++
+		}
+
+		if err := u.repository.MarkSent(us.Nickname, now); err != nil {
+			return sent, errors.Wrap(err, "repository.MarkSent()")
+		}
+	}
+
+	return sent, nil
+}
+
+func period(digestKind string) time.Duration {
+	if digestKind == user.DIGEST_WEEKLY {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+func body(notifications []*models.Notification, threads []*models.DigestThread) string {
+	var b strings.Builder
+
+	if len(notifications) != 0 {
+		b.WriteString("Unread notifications:\n")
+		for _, n := range notifications {
+			switch n.Kind {
+			case notification.KIND_MENTION:
+				fmt.Fprintf(&b, "  %s mentioned you in post %d (thread %d)\n", n.Actor, n.Post, n.Thread)
+			case notification.KIND_REPLY:
+				fmt.Fprintf(&b, "  %s replied to your post in thread %d\n", n.Actor, n.Thread)
+			case notification.KIND_SUBSCRIBED_FORUM:
+				fmt.Fprintf(&b, "  %s started thread %d\n", n.Actor, n.Thread)
+			default:
+				fmt.Fprintf(&b, "  %s posted in thread %d\n", n.Actor, n.Thread)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(threads) != 0 {
+		b.WriteString("Activity in your subscriptions:\n")
+		for _, t := range threads {
+			fmt.Fprintf(&b, "  %s (%s, thread %d): %d new posts\n", t.Title, t.Forum, t.Thread, t.Posts)
+		}
+	}
+
+	return b.String()
+}
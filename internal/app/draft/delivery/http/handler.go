@@ -0,0 +1,131 @@
+package draft_handler
+
+import (
+	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/draft"
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Handler struct {
+	usecase			draft.Usecase
+	sessionStore	sessions.Store
+}
+
+func NewDraftHandler(m *mux.Router, u draft.Usecase, sessionStore sessions.Store) {
+	handler := &Handler{
+		usecase:		u,
+		sessionStore:   sessionStore,
+	}
+
+	m.HandleFunc("/api/thread/{slug_or_id}/draft", handler.GetDraft).Methods(http.MethodGet)
+	m.HandleFunc("/api/thread/{slug_or_id}/draft", handler.SaveDraft).Methods(http.MethodPut)
+	m.HandleFunc("/api/thread/{slug_or_id}/draft", handler.RemoveDraft).Methods(http.MethodDelete)
+	m.HandleFunc("/api/user/{nickname}/drafts", handler.GetDrafts).Methods(http.MethodGet)
+}
+
+func (h *Handler) SaveDraft(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "DraftHandler.SaveDraft<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	d := new(models.Draft)
+	if err := json.NewDecoder(r.Body).Decode(d); err != nil {
+		err = errors.Wrapf(err, "DraftHandler.SaveDraft<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.Save(mux.Vars(r)["slug_or_id"], general.CurrentUser(r), d)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetDraft(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	res, err := h.usecase.Get(mux.Vars(r)["slug_or_id"], general.CurrentUser(r))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) RemoveDraft(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := h.usecase.Remove(mux.Vars(r)["slug_or_id"], general.CurrentUser(r)); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := new(models.ListParameters)
+	str := r.URL.Query().Get("limit")
+	if str != "" {
+		limit, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Limit = limit
+	}
+
+	str = r.URL.Query().Get("desc")
+	if str != "" {
+		desc, err := strconv.ParseBool(str)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Desc = desc
+	}
+
+	params.Since = r.URL.Query().Get("since")
+	if params.Since != "" {
+		if _, err := strconv.ParseInt(params.Since, 10, 64); err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	list, err := h.usecase.List(mux.Vars(r)["nickname"], general.CurrentUser(r), params)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, err error) {
+	if err.Error() == draft.UNAUTHORIZED {
+		general.Error(w, r, http.StatusUnauthorized, err)
+	} else if err.Error() == draft.FORBIDDEN {
+		general.Error(w, r, http.StatusForbidden, err)
+	} else if strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+	} else if err.Error() == draft.WRONG_INPUT {
+		general.Error(w, r, http.StatusBadRequest, err)
+	} else {
+		general.Error(w, r, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,10 @@
+package draft
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+type Repository interface {
+	Save(d *models.Draft) error
+	Find(nickname string, thread int64) (*models.Draft, error)
+	Delete(nickname string, thread int64) (bool, error)
+	List(nickname string, params *models.ListParameters) ([]*models.Draft, error)
+}
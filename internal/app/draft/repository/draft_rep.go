@@ -0,0 +1,101 @@
+package draft_rep
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/draft"
+	"github.com/efimovad/Forums.git/internal/models"
+	"strconv"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewDraftRepository(db *sql.DB) draft.Repository {
+	return &Repository{db}
+}
+
+// Save keeps a single draft per user and thread, overwriting the previous one.
+func (r *Repository) Save(d *models.Draft) error {
+	return r.db.QueryRow(
+		"INSERT INTO drafts (nickname, thread, parent, message) VALUES ($1, $2, $3, $4) " +
+			"ON CONFLICT (LOWER(nickname), thread) " +
+			"DO UPDATE SET parent = EXCLUDED.parent, message = EXCLUDED.message, updated = now() " +
+			"RETURNING id, created, updated",
+		d.Nickname,
+		d.Thread,
+		d.Parent,
+		d.Message,
+	).Scan(&d.ID, &d.Created, &d.Updated)
+}
+
+func (r *Repository) Find(nickname string, thread int64) (*models.Draft, error) {
+	d := new(models.Draft)
+	err := r.db.QueryRow(
+		`SELECT d.id, d.nickname, d.thread, COALESCE(t.slug, ''), t.title, d.parent, d.message, d.created, d.updated
+				FROM drafts d
+				JOIN threads t ON t.id = d.thread
+				WHERE LOWER(d.nickname) = LOWER($1) AND d.thread = $2`,
+		nickname,
+		thread,
+	).Scan(&d.ID, &d.Nickname, &d.Thread, &d.ThreadSlug, &d.ThreadTitle, &d.Parent, &d.Message, &d.Created, &d.Updated)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *Repository) Delete(nickname string, thread int64) (bool, error) {
+	res, err := r.db.Exec("DELETE FROM drafts WHERE LOWER(nickname) = LOWER($1) AND thread = $2", nickname, thread)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *Repository) List(nickname string, params *models.ListParameters) ([]*models.Draft, error) {
+	var since int64
+	if params.Since != "" {
+		var err error
+		if since, err = strconv.ParseInt(params.Since, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.Query(
+		`SELECT d.id, d.nickname, d.thread, COALESCE(t.slug, ''), t.title, d.parent, d.message, d.created, d.updated
+				FROM drafts d
+				JOIN threads t ON t.id = d.thread
+				WHERE LOWER(d.nickname) = LOWER($1) AND
+					($2 = 0 OR (NOT $3 AND d.id > $2) OR ($3 AND d.id < $2))
+				ORDER BY
+					CASE WHEN $3 THEN d.id END DESC,
+					CASE WHEN NOT $3 THEN d.id END ASC
+				LIMIT CASE WHEN $4 > 0 THEN $4 END;`,
+		nickname, since, params.Desc, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.Draft, 0)
+	for rows.Next() {
+		d := new(models.Draft)
+		err := rows.Scan(&d.ID, &d.Nickname, &d.Thread, &d.ThreadSlug, &d.ThreadTitle, &d.Parent, &d.Message,
+			&d.Created, &d.Updated)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, d)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,19 @@
+package draft
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+const (
+	NOT_FOUND = "Can't find draft for this thread"
+	USER_NOT_FOUND = "Can't find user by nickname: "
+	THREAD_NOT_FOUND = "Can't find such thread"
+	UNAUTHORIZED = "Log in to keep drafts"
+	FORBIDDEN = "Drafts can only be read by their owner"
+	WRONG_INPUT = "Draft message is empty"
+)
+
+type Usecase interface {
+	Save(slugOrID string, nickname string, d *models.Draft) (*models.Draft, error)
+	Get(slugOrID string, nickname string) (*models.Draft, error)
+	Remove(slugOrID string, nickname string) error
+	List(nickname string, caller string, params *models.ListParameters) ([]*models.Draft, error)
+}
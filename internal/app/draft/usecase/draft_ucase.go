@@ -0,0 +1,116 @@
+package draft_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/draft"
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+type DraftUcase struct {
+	repository	draft.Repository
+	forumUcase	forum.Usecase
+	userRep		user.Repository
+}
+
+func NewDraftUsecase(r draft.Repository, fu forum.Usecase, ur user.Repository) draft.Usecase {
+	return &DraftUcase{
+		repository: r,
+		forumUcase: fu,
+		userRep:	ur,
+	}
+}
+
+// Save stores the user's draft for the thread. The draft is removed once the
+// user publishes a post in that thread.
+func (u *DraftUcase) Save(slugOrID string, nickname string, d *models.Draft) (*models.Draft, error) {
+	us, thread, err := u.resolve(slugOrID, nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(d.Message) == "" {
+		return nil, errors.New(draft.WRONG_INPUT)
+	}
+
+	d.Nickname = us.Nickname
+	d.Thread = thread.ID
+	if err := u.repository.Save(d); err != nil {
+		return nil, errors.Wrap(err, "repository.Save()")
+	}
+
+	d.ThreadSlug = thread.Slug
+	d.ThreadTitle = thread.Title
+	return d, nil
+}
+
+func (u *DraftUcase) Get(slugOrID string, nickname string) (*models.Draft, error) {
+	us, thread, err := u.resolve(slugOrID, nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := u.repository.Find(us.Nickname, thread.ID)
+	if err != nil {
+		return nil, errors.New(draft.NOT_FOUND)
+	}
+	return d, nil
+}
+
+func (u *DraftUcase) Remove(slugOrID string, nickname string) error {
+	us, thread, err := u.resolve(slugOrID, nickname)
+	if err != nil {
+		return err
+	}
+
+	removed, err := u.repository.Delete(us.Nickname, thread.ID)
+	if err != nil {
+		return errors.Wrap(err, "repository.Delete()")
+	}
+	if !removed {
+		return errors.New(draft.NOT_FOUND)
+	}
+	return nil
+}
+
+// List shows a user's open drafts. Drafts are private, so only their owner
+// may list them.
+func (u *DraftUcase) List(nickname string, caller string, params *models.ListParameters) ([]*models.Draft, error) {
+	if caller == "" {
+		return nil, errors.New(draft.UNAUTHORIZED)
+	}
+
+	us, err := u.userRep.FindByName(nickname)
+	if err != nil {
+		return nil, errors.New(draft.USER_NOT_FOUND + nickname)
+	}
+
+	if !strings.EqualFold(us.Nickname, caller) {
+		return nil, errors.New(draft.FORBIDDEN)
+	}
+
+	list, err := u.repository.List(us.Nickname, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.List()")
+	}
+	return list, nil
+}
+
+func (u *DraftUcase) resolve(slugOrID string, nickname string) (*models.User, *models.Thread, error) {
+	if nickname == "" {
+		return nil, nil, errors.New(draft.UNAUTHORIZED)
+	}
+
+	us, err := u.userRep.FindByName(nickname)
+	if err != nil {
+		return nil, nil, errors.New(draft.USER_NOT_FOUND + nickname)
+	}
+
+	thread, err := u.forumUcase.GetThread(slugOrID)
+	if err != nil {
+		return nil, nil, errors.New(draft.THREAD_NOT_FOUND)
+	}
+	return us, thread, nil
+}
@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+)
+
+const (
+	BANNED = "banned"
+	LINKS = "links"
+	DUPLICATE = "duplicate"
+	NEW_ACCOUNT = "new_account"
+)
+
+const (
+	ACTION_FLAG = "flag"
+	ACTION_HOLD = "hold"
+	ACTION_REJECT = "reject"
+)
+
+const (
+	KIND_THREAD = "thread"
+	KIND_POST = "post"
+)
+
+const (
+	STATUS_PENDING = "pending"
+	STATUS_APPROVED = "approved"
+	STATUS_REJECTED = "rejected"
+)
+
+var severity = map[string]int{
+	ACTION_FLAG:	1,
+	ACTION_HOLD:	2,
+	ACTION_REJECT:	3,
+}
+
+// Content is what a filter looks at: a new thread or a single post.
+type Content struct {
+	Author	string
+	Title	string
+	Message	string
+}
+
+// Filter checks content against the forum settings and returns a non-empty
+// reason when it matches. What happens then is decided by the action
+// configured for its name.
+type Filter interface {
+	Name() string
+	Check(s *models.FilterSettings, c *Content) (string, error)
+}
+
+type Pipeline struct {
+	filters []Filter
+}
+
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters}
+}
+
+// Run passes the content through every enabled filter and returns the most
+// severe verdict, or nil if nothing matched.
+func (p *Pipeline) Run(s *models.FilterSettings, c *Content) (*models.FilterVerdict, error) {
+	var verdict *models.FilterVerdict
+	for _, f := range p.filters {
+		action := s.Actions[f.Name()]
+		if severity[action] == 0 || (verdict != nil && severity[action] <= severity[verdict.Action]) {
+			continue
+		}
+
+		reason, err := f.Check(s, c)
+		if err != nil {
+			return nil, errors.Wrap(err, f.Name())
+		}
+
+		if reason != "" {
+			verdict = &models.FilterVerdict{
+				Action:	action,
+				Filter:	f.Name(),
+				Reason:	reason,
+			}
+		}
+	}
+	return verdict, nil
+}
+
+// Validate refuses settings with unknown filters or actions, negative limits
+// and patterns that don't compile.
+func Validate(s *models.FilterSettings) error {
+	for name, action := range s.Actions {
+		if name != BANNED && name != LINKS && name != DUPLICATE && name != NEW_ACCOUNT {
+			return errors.New("unknown filter " + name)
+		}
+		if action != "" && severity[action] == 0 {
+			return errors.New("unknown action " + action)
+		}
+	}
+
+	if s.MaxLinks < 0 || s.DuplicateWindow < 0 || s.NewAccountAge < 0 || s.NewAccountPosts < 0 {
+		return errors.New("negative limit")
+	}
+
+	for _, pattern := range s.BannedPatterns {
+		if _, err := compile(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
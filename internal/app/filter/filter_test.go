@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"github.com/efimovad/Forums.git/internal/models"
+	"testing"
+	"time"
+)
+
+type fakeRepository struct {
+	Repository
+	duplicate	bool
+	created		time.Time
+	recent		int
+}
+
+func (r *fakeRepository) HasDuplicate(author string, message string, since time.Time) (bool, error) {
+	return r.duplicate, nil
+}
+
+func (r *fakeRepository) AccountCreated(author string) (time.Time, error) {
+	return r.created, nil
+}
+
+func (r *fakeRepository) CountRecent(author string, since time.Time) (int, error) {
+	return r.recent, nil
+}
+
+func TestBannedFilter(t *testing.T) {
+	s := &models.FilterSettings{BannedWords: []string{"spam"}, BannedPatterns: []string{`buy\s+now`}}
+	tests := []struct {
+		message	string
+		match	bool
+	}{
+		{"this is SPAM", true},
+		{"spammer", false},
+		{"buy   now", true},
+		{"buy later", false},
+	}
+
+	for _, tt := range tests {
+		reason, err := BannedFilter{}.Check(s, &Content{Message: tt.message})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (reason != "") != tt.match {
+			t.Errorf("Check(%q) = %q, want match %v", tt.message, reason, tt.match)
+		}
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	s := &models.FilterSettings{MaxLinks: 1}
+	if reason, _ := (LinkFilter{}).Check(s, &Content{Message: "see https://a.b"}); reason != "" {
+		t.Errorf("one link: got %q", reason)
+	}
+	if reason, _ := (LinkFilter{}).Check(s, &Content{Message: "see https://a.b and www.c.d"}); reason == "" {
+		t.Error("two links aren't matched")
+	}
+	if reason, _ := (LinkFilter{}).Check(&models.FilterSettings{}, &Content{Message: "http://a http://b"}); reason != "" {
+		t.Errorf("no limit: got %q", reason)
+	}
+}
+
+func TestNewAccountFilter(t *testing.T) {
+	s := &models.FilterSettings{NewAccountAge: 3600, NewAccountPosts: 2}
+	tests := []struct {
+		name	string
+		rep		*fakeRepository
+		match	bool
+	}{
+		{"new account below limit", &fakeRepository{created: time.Now(), recent: 1}, false},
+		{"new account at limit", &fakeRepository{created: time.Now(), recent: 2}, true},
+		{"old account", &fakeRepository{created: time.Now().Add(-2 * time.Hour), recent: 10}, false},
+		{"account without registration time", &fakeRepository{recent: 10}, false},
+	}
+
+	for _, tt := range tests {
+		reason, err := NewNewAccountFilter(tt.rep).Check(s, &Content{Author: "a"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (reason != "") != tt.match {
+			t.Errorf("%s: got %q, want match %v", tt.name, reason, tt.match)
+		}
+	}
+}
+
+func TestPipelineMostSevere(t *testing.T) {
+	rep := &fakeRepository{duplicate: true}
+	p := NewPipeline(BannedFilter{}, LinkFilter{}, NewDuplicateFilter(rep))
+	s := &models.FilterSettings{
+		BannedWords:		[]string{"spam"},
+		MaxLinks:			1,
+		DuplicateWindow:	60,
+		Actions: map[string]string{
+			BANNED:		ACTION_FLAG,
+			LINKS:		ACTION_REJECT,
+			DUPLICATE:	ACTION_HOLD,
+		},
+	}
+
+	verdict, err := p.Run(s, &Content{Message: "spam http://a http://b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict == nil || verdict.Filter != LINKS || verdict.Action != ACTION_REJECT {
+		t.Errorf("got %+v, want the links filter rejecting", verdict)
+	}
+
+	verdict, err = p.Run(s, &Content{Message: "spam"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict == nil || verdict.Filter != DUPLICATE || verdict.Action != ACTION_HOLD {
+		t.Errorf("got %+v, want the duplicate filter holding", verdict)
+	}
+
+	delete(s.Actions, DUPLICATE)
+	rep.duplicate = false
+	if verdict, _ := p.Run(s, &Content{Message: "fine"}); verdict != nil {
+		t.Errorf("clean content got %+v", verdict)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name	string
+		s		*models.FilterSettings
+		valid	bool
+	}{
+		{"empty", &models.FilterSettings{}, true},
+		{"known", &models.FilterSettings{Actions: map[string]string{BANNED: ACTION_HOLD, LINKS: ""}}, true},
+		{"unknown filter", &models.FilterSettings{Actions: map[string]string{"caps": ACTION_FLAG}}, false},
+		{"unknown action", &models.FilterSettings{Actions: map[string]string{BANNED: "ban"}}, false},
+		{"negative limit", &models.FilterSettings{MaxLinks: -1}, false},
+		{"bad pattern", &models.FilterSettings{BannedPatterns: []string{"("}}, false},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(tt.s); (err == nil) != tt.valid {
+			t.Errorf("%s: got %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package filter
+
+import (
+	"github.com/efimovad/Forums.git/internal/models"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	patterns sync.Map
+	linkRe = regexp.MustCompile(`(?i)\b(https?://|www\.)`)
+)
+
+// compile caches patterns, the same forum settings are checked on every post.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// BannedFilter matches banned words, case-insensitively and as whole words,
+// and banned regular expressions.
+type BannedFilter struct{}
+
+func (f BannedFilter) Name() string {
+	return BANNED
+}
+
+func (f BannedFilter) Check(s *models.FilterSettings, c *Content) (string, error) {
+	text := c.Title + "\n" + c.Message
+
+	for _, word := range s.BannedWords {
+		re, err := compile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+		if err != nil {
+			return "", err
+		}
+		if re.MatchString(text) {
+			return "contains banned word " + strconv.Quote(word), nil
+		}
+	}
+
+	for _, pattern := range s.BannedPatterns {
+		re, err := compile(pattern)
+		if err != nil {
+			return "", err
+		}
+		if re.MatchString(text) {
+			return "matches banned pattern " + strconv.Quote(pattern), nil
+		}
+	}
+	return "", nil
+}
+
+type LinkFilter struct{}
+
+func (f LinkFilter) Name() string {
+	return LINKS
+}
+
+func (f LinkFilter) Check(s *models.FilterSettings, c *Content) (string, error) {
+	if s.MaxLinks == 0 {
+		return "", nil
+	}
+
+	if n := len(linkRe.FindAllStringIndex(c.Message, -1)); n > s.MaxLinks {
+		return "contains " + strconv.Itoa(n) + " links, at most " + strconv.Itoa(s.MaxLinks) + " allowed", nil
+	}
+	return "", nil
+}
+
+// DuplicateFilter matches a message the author already posted within the
+// configured window.
+type DuplicateFilter struct {
+	repository Repository
+}
+
+func NewDuplicateFilter(r Repository) *DuplicateFilter {
+	return &DuplicateFilter{repository: r}
+}
+
+func (f *DuplicateFilter) Name() string {
+	return DUPLICATE
+}
+
+func (f *DuplicateFilter) Check(s *models.FilterSettings, c *Content) (string, error) {
+	if s.DuplicateWindow == 0 {
+		return "", nil
+	}
+
+	since := time.Now().Add(-time.Duration(s.DuplicateWindow) * time.Second)
+	found, err := f.repository.HasDuplicate(c.Author, c.Message, since)
+	if err != nil || !found {
+		return "", err
+	}
+	return "same message was already posted", nil
+}
+
+// NewAccountFilter throttles accounts younger than NewAccountAge to
+// NewAccountPosts threads and posts per hour.
+type NewAccountFilter struct {
+	repository Repository
+}
+
+func NewNewAccountFilter(r Repository) *NewAccountFilter {
+	return &NewAccountFilter{repository: r}
+}
+
+func (f *NewAccountFilter) Name() string {
+	return NEW_ACCOUNT
+}
+
+func (f *NewAccountFilter) Check(s *models.FilterSettings, c *Content) (string, error) {
+	if s.NewAccountAge == 0 || s.NewAccountPosts == 0 {
+		return "", nil
+	}
+
+	created, err := f.repository.AccountCreated(c.Author)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if created.IsZero() || created.Before(now.Add(-time.Duration(s.NewAccountAge) * time.Second)) {
+		return "", nil
+	}
+
+	n, err := f.repository.CountRecent(c.Author, now.Add(-time.Hour))
+	if err != nil {
+		return "", err
+	}
+
+	if n >= s.NewAccountPosts {
+		return "new accounts may post " + strconv.Itoa(s.NewAccountPosts) + " times per hour", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,26 @@
+package filter
+
+import (
+	"github.com/efimovad/Forums.git/internal/models"
+	"time"
+)
+
+type Repository interface {
+	FindSettings(forum int64) (*models.FilterSettings, error)
+	SaveSettings(forum int64, s *models.FilterSettings) error
+
+	HasDuplicate(author string, message string, since time.Time) (bool, error)
+	// AccountCreated returns the zero time for accounts that predate
+	// registration times.
+	AccountCreated(author string) (time.Time, error)
+	CountRecent(author string, since time.Time) (int, error)
+
+	Enqueue(item *models.ModerationItem) error
+	FindItem(id int64) (*models.ModerationItem, error)
+	Queue(forum string, params *models.ListParameters) ([]*models.ModerationItem, error)
+	// Resolve moves a pending item to status and reports whether it was
+	// still pending.
+	Resolve(id int64, status string, moderator string) (bool, error)
+	Reopen(id int64) error
+	SetPublished(id int64, published int64) error
+}
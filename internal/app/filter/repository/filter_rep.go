@@ -0,0 +1,224 @@
+package filter_rep
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/filter"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/lib/pq"
+	"strconv"
+	"time"
+)
+
+const columns = "id, kind, forum, COALESCE(thread, 0), parent, author, title, slug, message, tags, quotes, " +
+	"action, filter, reason, status, published, moderator, created"
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewFilterRepository(db *sql.DB) filter.Repository {
+	return &Repository{db}
+}
+
+func (r *Repository) FindSettings(forum int64) (*models.FilterSettings, error) {
+	var raw []byte
+	if err := r.db.QueryRow("SELECT settings FROM forum_filters WHERE forum = $1", forum).Scan(&raw); err != nil {
+		return nil, err
+	}
+
+	s := new(models.FilterSettings)
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *Repository) SaveSettings(forum int64, s *models.FilterSettings) error {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		"INSERT INTO forum_filters (forum, settings) VALUES ($1, $2) " +
+			"ON CONFLICT (forum) DO UPDATE SET settings = EXCLUDED.settings",
+		forum,
+		string(raw),
+	)
+	return err
+}
+
+func (r *Repository) HasDuplicate(author string, message string, since time.Time) (bool, error) {
+	var found bool
+	err := r.db.QueryRow(
+		`SELECT EXISTS (
+					SELECT 1 FROM posts
+						WHERE LOWER(author) = LOWER($1) AND message = $2 AND created >= $3
+				) OR EXISTS (
+					SELECT 1 FROM threads
+						WHERE LOWER(author) = LOWER($1) AND message = $2 AND created >= $3 AND moved_to IS NULL
+				)`,
+		author,
+		message,
+		since,
+	).Scan(&found)
+	return found, err
+}
+
+func (r *Repository) AccountCreated(author string) (time.Time, error) {
+	var created pq.NullTime
+	err := r.db.QueryRow("SELECT created FROM users WHERE LOWER(nickname) = LOWER($1)", author).Scan(&created)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return created.Time, err
+}
+
+func (r *Repository) CountRecent(author string, since time.Time) (int, error) {
+	var n int
+	err := r.db.QueryRow(
+		`SELECT
+					(SELECT COUNT(*) FROM posts WHERE LOWER(author) = LOWER($1) AND created >= $2) +
+					(SELECT COUNT(*) FROM threads WHERE LOWER(author) = LOWER($1) AND created >= $2 AND moved_to IS NULL)`,
+		author,
+		since,
+	).Scan(&n)
+	return n, err
+}
+
+func (r *Repository) Enqueue(item *models.ModerationItem) error {
+	var thread interface{}
+	if item.Thread != 0 {
+		thread = item.Thread
+	}
+
+	return r.db.QueryRow(
+		"INSERT INTO moderation_queue (kind, forum, thread, parent, author, title, slug, message, tags, quotes, " +
+			"action, filter, reason, published) " +
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, status, created",
+		item.Kind,
+		item.Forum,
+		thread,
+		item.Parent,
+		item.Author,
+		item.Title,
+		item.Slug,
+		item.Message,
+		pq.Array(item.Tags),
+		pq.Array(item.Quotes),
+		item.Action,
+		item.Filter,
+		item.Reason,
+		item.Published,
+	).Scan(&item.ID, &item.Status, &item.Created)
+}
+
+func (r *Repository) FindItem(id int64) (*models.ModerationItem, error) {
+	return scan(r.db.QueryRow("SELECT " + columns + " FROM moderation_queue WHERE id = $1", id))
+}
+
+// Queue lists the items of a forum still waiting for a moderator.
+func (r *Repository) Queue(forum string, params *models.ListParameters) ([]*models.ModerationItem, error) {
+	var since int64
+	if params.Since != "" {
+		var err error
+		if since, err = strconv.ParseInt(params.Since, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.Query(
+		`SELECT ` + columns + `
+				FROM moderation_queue
+				WHERE LOWER(forum) = LOWER($1) AND status = $5 AND
+					($2 = 0 OR (NOT $3 AND id > $2) OR ($3 AND id < $2))
+				ORDER BY
+					CASE WHEN $3 THEN id END DESC,
+					CASE WHEN NOT $3 THEN id END ASC
+				LIMIT CASE WHEN $4 > 0 THEN $4 END;`,
+		forum, since, params.Desc, params.Limit, filter.STATUS_PENDING)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.ModerationItem, 0)
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, item)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) Resolve(id int64, status string, moderator string) (bool, error) {
+	res, err := r.db.Exec(
+		"UPDATE moderation_queue SET status = $2, moderator = $3 WHERE id = $1 AND status = $4",
+		id,
+		status,
+		moderator,
+		filter.STATUS_PENDING,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *Repository) Reopen(id int64) error {
+	_, err := r.db.Exec(
+		"UPDATE moderation_queue SET status = $2, moderator = '' WHERE id = $1",
+		id,
+		filter.STATUS_PENDING,
+	)
+	return err
+}
+
+func (r *Repository) SetPublished(id int64, published int64) error {
+	_, err := r.db.Exec("UPDATE moderation_queue SET published = $2 WHERE id = $1", id, published)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scan(row scanner) (*models.ModerationItem, error) {
+	item := new(models.ModerationItem)
+	err := row.Scan(
+		&item.ID,
+		&item.Kind,
+		&item.Forum,
+		&item.Thread,
+		&item.Parent,
+		&item.Author,
+		&item.Title,
+		&item.Slug,
+		&item.Message,
+		pq.Array(&item.Tags),
+		pq.Array(&item.Quotes),
+		&item.Action,
+		&item.Filter,
+		&item.Reason,
+		&item.Status,
+		&item.Published,
+		&item.Moderator,
+		&item.Created,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -2,15 +2,18 @@ package forum_handler
 
 import (
 	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/filter"
 	"github.com/efimovad/Forums.git/internal/app/forum"
 	"github.com/efimovad/Forums.git/internal/app/general"
 	"github.com/efimovad/Forums.git/internal/models"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 	"github.com/pkg/errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Handler struct {
@@ -25,6 +28,8 @@ func NewForumHandler(m *mux.Router, u forum.Usecase, sessionStore sessions.Store
 	}
 
 	m.HandleFunc("/api/forum/create", handler.CreateForum).Methods(http.MethodPost)
+	m.HandleFunc("/api/forums", handler.GetForums).Methods(http.MethodGet)
+	m.HandleFunc("/api/forum/{slug}/parent", handler.SetParent).Methods(http.MethodPost)
 	m.HandleFunc("/api/forum/{slug}/create", handler.CreateThread).Methods(http.MethodPost)
 	m.HandleFunc("/api/forum/{slug}/details", handler.GetForum).Methods(http.MethodGet)
 	m.HandleFunc("/api/forum/{slug}/threads", handler.GetThreads).Methods(http.MethodGet)
@@ -32,12 +37,28 @@ func NewForumHandler(m *mux.Router, u forum.Usecase, sessionStore sessions.Store
 
 	m.HandleFunc("/api/thread/{slug_or_id}/create", handler.CreatePost).Methods(http.MethodPost)
 	m.HandleFunc("/api/thread/{slug_or_id}/vote", handler.VoteThread).Methods(http.MethodPost)
+	m.HandleFunc("/api/thread/{slug_or_id}/vote", handler.GetVote).Methods(http.MethodGet)
+	m.HandleFunc("/api/thread/{slug_or_id}/vote", handler.DeleteVote).Methods(http.MethodDelete)
 	m.HandleFunc("/api/thread/{slug_or_id}/details", handler.GetThread).Methods(http.MethodGet)
 	m.HandleFunc("/api/thread/{slug_or_id}/details", handler.UpdateThread).Methods(http.MethodPost)
+	m.HandleFunc("/api/thread/{slug_or_id}/move", handler.MoveThread).Methods(http.MethodPost)
+	m.HandleFunc("/api/thread/{slug_or_id}/rename", handler.RenameThread).Methods(http.MethodPost)
+	m.HandleFunc("/api/forum/{slug}/rename", handler.RenameForum).Methods(http.MethodPost)
+	m.HandleFunc("/api/forum/{slug}/tags", handler.GetTags).Methods(http.MethodGet)
+	m.HandleFunc("/api/forum/{slug}/filters", handler.GetFilters).Methods(http.MethodGet)
+	m.HandleFunc("/api/forum/{slug}/filters", handler.SetFilters).Methods(http.MethodPost)
+	m.HandleFunc("/api/forum/{slug}/moderation", handler.GetModerationQueue).Methods(http.MethodGet)
+	m.HandleFunc("/api/moderation/{id}/approve", handler.Approve).Methods(http.MethodPost)
+	m.HandleFunc("/api/moderation/{id}/reject", handler.Reject).Methods(http.MethodPost)
+	m.HandleFunc("/api/thread/{slug_or_id}/merge", handler.MergeThreads).Methods(http.MethodPost)
+	m.HandleFunc("/api/thread/{slug_or_id}/audit", handler.GetThreadAudit).Methods(http.MethodGet)
+	m.HandleFunc("/api/post/{id}/split", handler.SplitThread).Methods(http.MethodPost)
 	m.HandleFunc("/api/thread/{slug_or_id}/posts", handler.GetPosts).Methods(http.MethodGet)
+	m.HandleFunc("/api/thread/{slug_or_id}/read", handler.MarkRead).Methods(http.MethodPost)
 
 	m.HandleFunc("/api/post/{id}/details", handler.GetPost).Methods(http.MethodGet)
 	m.HandleFunc("/api/post/{id}/details", handler.UpdatePost).Methods(http.MethodPost)
+	m.HandleFunc("/api/post/{id}/vote", handler.VotePost).Methods(http.MethodPost)
 }
 
 func (h *Handler) CreateForum(w http.ResponseWriter, r *http.Request) {
@@ -65,6 +86,8 @@ func (h *Handler) CreateForum(w http.ResponseWriter, r *http.Request) {
 			general.Respond(w, r, http.StatusConflict, exForum)
 		} else if strings.Contains(err.Error(), forum.NOT_FOUND_ERR) {
 			general.Error(w,r, http.StatusNotFound, errors.New(forum.NOT_FOUND_ERR + newForum.User))
+		} else if strings.Contains(err.Error(), forum.PARENT_NOT_FOUND) {
+			general.Error(w,r, http.StatusNotFound, err)
 		} else {
 			general.Error(w,r, http.StatusInternalServerError, err)
 		}
@@ -106,11 +129,20 @@ func (h *Handler) CreateThread(w http.ResponseWriter, r *http.Request) {
 			general.Respond(w,r, http.StatusConflict, exThread)
 		} else if strings.Contains(err.Error(), forum.NOT_FOUND_ERR) {
 			general.Error(w,r, http.StatusNotFound, errors.New(forum.NOT_FOUND_ERR + newThread.Author))
+		} else if err.Error() == forum.WRONG_TAGS {
+			general.Error(w,r, http.StatusBadRequest, err)
+		} else if strings.HasPrefix(err.Error(), forum.REJECTED) {
+			general.Error(w,r, http.StatusForbidden, err)
 		} else {
 			general.Error(w,r, http.StatusInternalServerError, err)
 		}
 		return
 	}
+
+	if newThread.Moderation != nil && newThread.Moderation.Action == filter.ACTION_HOLD {
+		general.Respond(w, r, http.StatusAccepted, newThread)
+		return
+	}
 	general.Respond(w, r, http.StatusCreated, newThread)
 }
 
@@ -125,9 +157,58 @@ func (h *Handler) GetForum(w http.ResponseWriter, r *http.Request) {
 		general.Error(w, r, http.StatusNotFound, errors.New("Can't find forum by slug: " + slug))
 		return
 	}
+
+	if general.Canonical(w, r, "slug", f.Slug) {
+		return
+	}
+
+	if general.NotModified(w, r, general.ETag(f), f.Modified) {
+		return
+	}
 	general.Respond(w, r, http.StatusOK, f)
 }
 
+func (h *Handler) GetForums(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	list, err := h.usecase.GetForumTree()
+	if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) SetParent(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ForumHandler.SetParent<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	input := new(struct {
+		Parent	string	`json:"parent"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		err = errors.Wrapf(err, "ForumHandler.SetParent<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.SetParent(mux.Vars(r)["slug"], input.Parent, general.CurrentUser(r))
+	if err != nil && err.Error() == forum.FORUM_CYCLE {
+		general.Error(w, r, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
 func (h *Handler) GetThreads(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -167,13 +248,42 @@ func (h *Handler) GetThreads(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params.Since = r.URL.Query().Get("since")
+	params.User = general.CurrentUser(r)
+	params.Tags = r.URL.Query()["tag"]
+	params.TagMode = r.URL.Query().Get("tag_mode")
+
+	str = r.URL.Query().Get("subforums")
+	if str != "" {
+		subforums, err := strconv.ParseBool(str)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Subforums = subforums
+	}
+
+	if params.TagMode != "" && params.TagMode != "all" && params.TagMode != "any" {
+		general.Error(w, r, http.StatusBadRequest, errors.New(forum.WRONG_INPUT))
+		return
+	}
+
+	if f, err := h.usecase.GetForum(slug); err == nil && general.Canonical(w, r, "slug", f.Slug) {
+		return
+	}
 
 	list, err := h.usecase.GetThreads(slug, params)
-	if err != nil {
+	if err != nil && strings.Contains(err.Error(), forum.USER_NOT_FOUND) {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
 		general.Error(w, r, http.StatusNotFound, errors.New("Can't find forum by slug: " + slug))
 		return
 	}
 
+	if renderHTML(r) {
+		h.usecase.RenderThreads(list...)
+	}
+
 	if len(list) == 0 {
 		general.Respond(w, r, http.StatusOK,  []string{})
 		return
@@ -181,6 +291,25 @@ func (h *Handler) GetThreads(w http.ResponseWriter, r *http.Request) {
 	general.Respond(w, r, http.StatusOK, list)
 }
 
+func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	slug := mux.Vars(r)["slug"]
+	if f, err := h.usecase.GetForum(slug); err == nil && general.Canonical(w, r, "slug", f.Slug) {
+		return
+	}
+
+	list, err := h.usecase.GetTags(slug)
+	if err != nil && err.Error() == forum.NOT_FOUND {
+		general.Error(w, r, http.StatusNotFound, errors.New("Can't find forum by slug: " + slug))
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
 func (h *Handler) UpdateThread(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -203,8 +332,26 @@ func (h *Handler) UpdateThread(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	slug := vars["slug_or_id"]
 
+	// If-Match carries the version the client edited; the update itself
+	// checks it, so nobody can slip in between a check and the write.
+	version, ok, err := general.IfMatchVersion(r)
+	if err != nil {
+		general.Error(w, r, http.StatusPreconditionFailed, err)
+		return
+	} else if ok {
+		thread.Version = version
+	}
+
 	res, err := h.usecase.UpdateThread(slug, thread)
-	if err != nil && strings.Contains(err.Error(), "Can't find") {
+	if err != nil && err.Error() == forum.VERSION_CONFLICT && ok {
+		w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
+		general.Error(w, r, http.StatusPreconditionFailed, errors.New(general.PRECONDITION_FAILED))
+		return
+	} else if err != nil && err.Error() == forum.VERSION_CONFLICT {
+		w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
+		general.Respond(w, r, http.StatusConflict, res)
+		return
+	} else if err != nil && strings.Contains(err.Error(), "Can't find") {
 		general.Error(w, r, http.StatusNotFound, err)
 		return
 	} else if err != nil {
@@ -212,9 +359,188 @@ func (h *Handler) UpdateThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) MoveThread(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ForumHandler.MoveThread<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	input := new(struct {
+		Forum	string	`json:"forum"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		err = errors.Wrapf(err, "ForumHandler.MoveThread<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.MoveThread(mux.Vars(r)["slug_or_id"], input.Forum, general.CurrentUser(r))
+	if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
 	general.Respond(w, r, http.StatusOK, res)
 }
 
+func (h *Handler) RenameForum(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ForumHandler.RenameForum<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	input := new(struct {
+		Slug	string	`json:"slug"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		err = errors.Wrapf(err, "ForumHandler.RenameForum<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.RenameForum(mux.Vars(r)["slug"], input.Slug, general.CurrentUser(r))
+	if err != nil && err.Error() == forum.FORUM_CONFLICT {
+		general.Error(w, r, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) RenameThread(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ForumHandler.RenameThread<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	input := new(struct {
+		Slug	string	`json:"slug"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		err = errors.Wrapf(err, "ForumHandler.RenameThread<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.RenameThread(mux.Vars(r)["slug_or_id"], input.Slug, general.CurrentUser(r))
+	if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) MergeThreads(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ForumHandler.MergeThreads<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	input := new(struct {
+		Into	string	`json:"into"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		err = errors.Wrapf(err, "ForumHandler.MergeThreads<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.MergeThreads(mux.Vars(r)["slug_or_id"], input.Into, general.CurrentUser(r))
+	if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) SplitThread(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ForumHandler.SplitThread<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	thread := new(models.Thread)
+	if err := json.NewDecoder(r.Body).Decode(thread); err != nil {
+		err = errors.Wrapf(err, "ForumHandler.SplitThread<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.SplitThread(id, thread, general.CurrentUser(r))
+	if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
+	general.Respond(w, r, http.StatusCreated, res)
+}
+
+func (h *Handler) GetThreadAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	list, err := h.usecase.GetThreadAudit(mux.Vars(r)["slug_or_id"])
+	if err != nil && strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) moderationError(w http.ResponseWriter, r *http.Request, err error) {
+	if err.Error() == forum.FORBIDDEN {
+		general.Error(w, r, http.StatusForbidden, err)
+	} else if err.Error() == forum.THREAD_MOVED || err.Error() == forum.THREAD_CONFLICT {
+		general.Error(w, r, http.StatusConflict, err)
+	} else if err.Error() == forum.WRONG_INPUT {
+		general.Error(w, r, http.StatusBadRequest, err)
+	} else if strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+	} else {
+		general.Error(w, r, http.StatusInternalServerError, err)
+	}
+}
+
 func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -242,18 +568,37 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	if err != nil && strings.Contains(err.Error(), forum.PARENT_POST_CONFLICT) {
 		general.Error(w, r, http.StatusConflict, err)
 		return
+	} else if err != nil && err.Error() == forum.THREAD_MOVED {
+		general.Error(w, r, http.StatusConflict, err)
+		return
 	} else if err != nil && strings.Contains(err.Error(), "Can't find post thread by") {
 		general.Error(w, r, http.StatusNotFound, err)
 		return
 	} else if err != nil && strings.Contains(err.Error(), forum.NOT_FOUND_ERR) {
 		general.Error(w, r, http.StatusNotFound, err)
 		return
+	} else if err != nil && strings.Contains(err.Error(), forum.QUOTE_NOT_FOUND) {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil && strings.HasPrefix(err.Error(), forum.REJECTED) {
+		general.Error(w, r, http.StatusForbidden, err)
+		return
 	} else if err != nil {
 		general.Error(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
-	general.Respond(w, r, http.StatusCreated, list)
+	// Nothing is published when every post was held for moderation.
+	status := http.StatusAccepted
+	for _, post := range list {
+		if post.ID != 0 {
+			status = http.StatusCreated
+		}
+	}
+	if len(list) == 0 {
+		status = http.StatusCreated
+	}
+	general.Respond(w, r, status, list)
 }
 
 func (h *Handler) VoteThread(w http.ResponseWriter, r *http.Request) {
@@ -291,20 +636,161 @@ func (h *Handler) VoteThread(w http.ResponseWriter, r *http.Request) {
 	general.Respond(w, r, http.StatusOK, thread)
 }
 
+func (h *Handler) GetVote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	slugOrID := vars["slug_or_id"]
+
+	vote, err := h.usecase.GetVote(slugOrID, r.URL.Query().Get("nickname"))
+	if err != nil && strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	general.Respond(w, r, http.StatusOK, vote)
+}
+
+func (h *Handler) DeleteVote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	slugOrID := vars["slug_or_id"]
+
+	thread, err := h.usecase.DeleteVote(slugOrID, r.URL.Query().Get("nickname"))
+	if err != nil && strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	general.Respond(w, r, http.StatusOK, thread)
+}
+
+func (h *Handler) VotePost(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ForumHandler.VotePost<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	vote := new(models.Vote)
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(vote)
+	if err != nil {
+		err = errors.Wrapf(err, "ForumHandler.VotePost<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+	vote.Post = id
+
+	post, err := h.usecase.CreatePostVote(vote)
+	if err != nil && err.Error() == forum.POST_NOT_FOUND {
+		general.Error(w, r, http.StatusNotFound, errors.New("Can't find post by id: " + strconv.FormatInt(id, 10)))
+		return
+	} else if err != nil && strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil && err.Error() == forum.WRONG_INPUT {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	general.Respond(w, r, http.StatusOK, post)
+}
+
 func (h *Handler) GetThread(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	vars := mux.Vars(r)
 	slug := vars["slug_or_id"]
 
-	t, err := h.usecase.GetThread(slug)
+	t, err := h.usecase.GetThreadDetails(slug)
 	if err != nil {
 		general.Error(w, r, http.StatusNotFound, err)
 		return
 	}
+
+	if threadRedirect(w, r, t) {
+		return
+	}
+
+	if renderHTML(r) {
+		h.usecase.RenderThreads(t)
+	}
+
+	if general.NotModified(w, r, general.VersionETag(t.Version, t.Modified), t.Modified) {
+		return
+	}
 	general.Respond(w, r, http.StatusOK, t)
 }
 
+func (h *Handler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ForumHandler.MarkRead<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	vars := mux.Vars(r)
+	slugOrID := vars["slug_or_id"]
+
+	marker := new(models.ReadMarker)
+	if err := json.NewDecoder(r.Body).Decode(marker); err != nil && err != io.EOF {
+		err = errors.Wrapf(err, "ForumHandler.MarkRead<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+	// The marker is always the caller's; a nickname in the body may only
+	// repeat it.
+	caller := general.CurrentUser(r)
+	if caller == "" {
+		general.Error(w, r, http.StatusUnauthorized, errors.New(forum.UNAUTHORIZED))
+		return
+	} else if marker.Nickname != "" && !strings.EqualFold(marker.Nickname, caller) {
+		general.Error(w, r, http.StatusForbidden, errors.New(forum.MARKER_FORBIDDEN))
+		return
+	}
+
+	res, err := h.usecase.MarkRead(slugOrID, caller, marker.Post)
+	if err != nil && err.Error() == forum.POST_NOT_FOUND {
+		general.Error(w, r, http.StatusNotFound, errors.New("Can't find post by id: " + strconv.FormatInt(marker.Post, 10)))
+		return
+	} else if err != nil && strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil && err.Error() == forum.POST_THREAD_CONFLICT {
+		general.Error(w, r, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	general.Respond(w, r, http.StatusOK, res)
+}
+
 func (h *Handler) GetPosts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -337,11 +823,22 @@ func (h *Handler) GetPosts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params.Since = r.URL.Query().Get("since")
+	if params.Since != "" {
+		if _, err := strconv.ParseInt(params.Since, 10, 64); err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
 	params.Sort = r.URL.Query().Get("sort")
 	if params.Sort == "" {
 		params.Sort = "flat"
 	}
 
+	if t, err := h.usecase.GetThread(currForum); err == nil && threadRedirect(w, r, t) {
+		return
+	}
+
 	list, err := h.usecase.GetPosts(currForum, params)
 	if err != nil && strings.Contains(err.Error(), "Can't find") {
 		general.Error(w, r, http.StatusNotFound, err)
@@ -352,9 +849,27 @@ func (h *Handler) GetPosts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if len(list) == 0 {
+		if general.NotModified(w, r, general.ETag([]string{}), time.Time{}) {
+			return
+		}
 		general.Respond(w, r, http.StatusOK,  []string{})
 		return
 	}
+
+	if renderHTML(r) {
+		h.usecase.RenderPosts(list...)
+	}
+
+	var modified time.Time
+	for _, p := range list {
+		if p.Modified.After(modified) {
+			modified = p.Modified
+		}
+	}
+
+	if general.NotModified(w, r, general.ETag(list), modified) {
+		return
+	}
 	general.Respond(w, r, http.StatusOK, list)
 }
 
@@ -391,6 +906,10 @@ func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
 
 	params.Since = r.URL.Query().Get("since")
 
+	if f, err := h.usecase.GetForum(currForum); err == nil && general.Canonical(w, r, "slug", f.Slug) {
+		return
+	}
+
 	list, err := h.usecase.GetUsers(currForum, params)
 	if err != nil && err.Error() == forum.NOT_FOUND {
 		general.Error(w, r, http.StatusNotFound, errors.New("Can't find forum by slug: " + currForum))
@@ -430,6 +949,18 @@ func (h *Handler) GetPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if renderHTML(r) {
+		h.usecase.RenderPosts(post.Post)
+		if post.Thread != nil {
+			h.usecase.RenderThreads(post.Thread)
+		}
+	}
+
+	// The related objects and quotes change without touching the post, so
+	// neither its version nor its modified time can validate the response.
+	if general.NotModified(w, r, general.ContentETag(post.Post.Version, post), time.Time{}) {
+		return
+	}
 	general.Respond(w, r, http.StatusOK, post)
 }
 
@@ -462,8 +993,25 @@ func (h *Handler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	post.ID = id
+
+	version, ok, err := general.IfMatchVersion(r)
+	if err != nil {
+		general.Error(w, r, http.StatusPreconditionFailed, err)
+		return
+	} else if ok {
+		post.Version = version
+	}
+
 	res, err := h.usecase.UpdatePost(post)
-	if err != nil && err.Error() == forum.POST_NOT_FOUND {
+	if err != nil && err.Error() == forum.VERSION_CONFLICT && ok {
+		w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
+		general.Error(w, r, http.StatusPreconditionFailed, errors.New(general.PRECONDITION_FAILED))
+		return
+	} else if err != nil && err.Error() == forum.VERSION_CONFLICT {
+		w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
+		general.Respond(w, r, http.StatusConflict, res)
+		return
+	} else if err != nil && err.Error() == forum.POST_NOT_FOUND {
 		general.Error(w, r, http.StatusNotFound, errors.New("Can't find post by id: " + strconv.FormatInt(id, 10)))
 		return
 	} else if err != nil {
@@ -471,5 +1019,128 @@ func (h *Handler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", general.VersionETag(res.Version, res.Modified))
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+// renderHTML reports whether the client asked for rendered messages via ?render=html.
+func renderHTML(r *http.Request) bool {
+	return r.URL.Query().Get("render") == "html"
+}
+
+// threadRedirect sends clients that addressed the thread by an outdated
+// slug to its current one. Requests by id are never redirected.
+func threadRedirect(w http.ResponseWriter, r *http.Request, t *models.Thread) bool {
+	if _, err := strconv.ParseInt(mux.Vars(r)["slug_or_id"], 10, 64); err == nil {
+		return false
+	}
+	return general.Canonical(w, r, "slug_or_id", t.Slug)
+}
+
+func (h *Handler) GetFilters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	res, err := h.usecase.GetFilters(mux.Vars(r)["slug"], general.CurrentUser(r))
+	if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) SetFilters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ForumHandler.SetFilters<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	s := new(models.FilterSettings)
+	if err := json.NewDecoder(r.Body).Decode(s); err != nil {
+		err = errors.Wrapf(err, "ForumHandler.SetFilters<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.SetFilters(mux.Vars(r)["slug"], general.CurrentUser(r), s)
+	if err != nil && strings.HasPrefix(err.Error(), forum.WRONG_FILTERS) {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := new(models.ListParameters)
+	str := r.URL.Query().Get("limit")
+	if str != "" {
+		limit, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Limit = limit
+	}
+
+	str = r.URL.Query().Get("desc")
+	if str != "" {
+		desc, err := strconv.ParseBool(str)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Desc = desc
+	}
+
+	params.Since = r.URL.Query().Get("since")
+	if params.Since != "" {
+		if _, err := strconv.ParseInt(params.Since, 10, 64); err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	list, err := h.usecase.GetModerationQueue(mux.Vars(r)["slug"], general.CurrentUser(r), params)
+	if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, true)
+}
+
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, false)
+}
+
+func (h *Handler) moderate(w http.ResponseWriter, r *http.Request, approve bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.Moderate(id, general.CurrentUser(r), approve)
+	if err != nil && (err.Error() == forum.ITEM_RESOLVED || strings.Contains(err.Error(), forum.THREAD_CONFLICT) ||
+		strings.Contains(err.Error(), forum.PARENT_POST_CONFLICT)) {
+		general.Error(w, r, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		h.moderationError(w, r, err)
+		return
+	}
 	general.Respond(w, r, http.StatusOK, res)
-}
\ No newline at end of file
+}
@@ -7,22 +7,40 @@ import (
 type Repository interface {
 	CreateForum(forum *models.Forum) error
 	FindBySlug(slug string) (*models.Forum, error)
+	ListForums() ([]*models.Forum, error)
+	SetParent(f *models.Forum, parent *models.Forum) error
+	RenameForum(f *models.Forum, slug string) error
 	GetUsers(id int64, params models.ListParameters) ([]*models.User, error)
 
 	CreateThread(thread *models.Thread) error
 	GetThreads(slug string, params *models.ListParameters) ([]*models.Thread, error)
+	FindTags(threads []int64) (map[int64][]string, error)
+	ForumTags(slug string) ([]*models.TagCount, error)
 	FindThread(id int64) (*models.Thread, error)
 	FindThreadBySlug(slug string) (*models.Thread, error)
+	FindThreads(ids []int64) (map[int64]*models.Thread, error)
 	UpdateThread(thread *models.Thread) error
+	RenameThread(thread *models.Thread, slug string) error
+	MoveThread(thread *models.Thread, to *models.Forum, moderator string) (*models.Thread, error)
+	MergeThreads(from *models.Thread, into *models.Thread, moderator string) error
+	SplitThread(post *models.Post, from *models.Thread, thread *models.Thread, moderator string) error
+	ThreadAudit(thread int64) ([]*models.ThreadAudit, error)
+	MarkRead(nickname string, thread *models.Thread, post int64) (*models.ReadMarker, error)
+	UnreadCounts(nickname string, threads []int64) (map[int64]int64, error)
 
 	CreatePosts(posts []*models.Post, thread *models.Thread) error
 	FindPost(id int64) (*models.Post, error)
+	FindPosts(ids []int64) (map[int64]*models.Post, error)
 	//FindPostBySlug(slug string) (*models.Post, error)
 	GetPosts(thread *models.Thread, params *models.ListParameters) ([]*models.Post, error)
 	UpdatePost(post *models.Post) error
+	FindQuotes(posts []int64) (map[int64][]*models.Quote, error)
+	FindQuotingPosts(id int64) ([]*models.Post, error)
 
 	CreateVote(vote *models.Vote, thread *models.Thread) (int64, error)
-	//FindVote(thread string, nickname string) (*models.Vote, error)
+	CreatePostVote(vote *models.Vote, post *models.Post) (int64, error)
+	FindVote(nickname string, thread *models.Thread) (*models.Vote, error)
+	DeleteVote(nickname string, thread *models.Thread) error
 	//UpdateVote(vote *models.Vote) (int64, error)
 
 	FindUser(nickname string) (*models.User, error)
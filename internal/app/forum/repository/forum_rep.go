@@ -29,10 +29,11 @@ func (r *Repository) CreateForum(forum *models.Forum) error {
 	}
 
 	err = tx.QueryRow(
-		"INSERT INTO forums (slug, title, \"user\") VALUES ($1, $2, $3) RETURNING id",
+		"INSERT INTO forums (slug, title, \"user\", parent) VALUES ($1, $2, $3, NULLIF($4, 0)) RETURNING id",
 		forum.Slug,
 		forum.Title,
 		forum.User,
+		forum.ParentID,
 	).Scan(&forum.ID)
 	if err != nil {
 		tx.Rollback()
@@ -71,6 +72,11 @@ func (r *Repository) CreateThread(thread *models.Thread) error {
 		return err
 	}
 
+	if err = saveTags(tx, thread); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -78,15 +84,26 @@ func (r *Repository) CreateThread(thread *models.Thread) error {
 	return nil
 }
 
+// FindBySlug also accepts slugs the forum had before a rename and returns
+// the forum under its current slug.
 func (r *Repository) FindBySlug(slug string) (*models.Forum, error) {
+	f, err := r.findForum("LOWER(slug) = LOWER($1)", slug)
+	if err == sql.ErrNoRows {
+		if id, aliasErr := r.findAlias("forum", slug); aliasErr == nil {
+			return r.findForum("id = $1", id)
+		}
+	}
+	return f, err
+}
+
+func (r *Repository) findForum(condition string, arg interface{}) (*models.Forum, error) {
 	f := new(models.Forum)
 
 	if err := r.db.QueryRow(
-		"SELECT id, slug, title, \"user\", " +
-			"(SELECT COUNT(*) FROM threads WHERE LOWER(forum) = LOWER($1)), " +
-			"posts " +
-			"FROM forums WHERE LOWER(slug) = LOWER($1)",
-		slug,
+		"SELECT id, slug, title, \"user\", threads, posts, modified, COALESCE(parent, 0), " +
+			"COALESCE((SELECT p.slug FROM forums p WHERE p.id = forums.parent), '') " +
+			"FROM forums WHERE " + condition,
+		arg,
 	).Scan(
 		&f.ID,
 		&f.Slug,
@@ -94,12 +111,84 @@ func (r *Repository) FindBySlug(slug string) (*models.Forum, error) {
 		&f.User,
 		&f.Threads,
 		&f.Posts,
+		&f.Modified,
+		&f.ParentID,
+		&f.Parent,
 	); err != nil {
 		return nil, err
 	}
 	return f, nil
 }
 
+func (r *Repository) ListForums() ([]*models.Forum, error) {
+	rows, err := r.db.Query(
+		"SELECT id, slug, title, \"user\", threads, posts, modified, COALESCE(parent, 0) " +
+			"FROM forums ORDER BY LOWER(slug)",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	forums := make([]*models.Forum, 0)
+	for rows.Next() {
+		f := new(models.Forum)
+		err := rows.Scan(&f.ID, &f.Slug, &f.Title, &f.User, &f.Threads, &f.Posts, &f.Modified, &f.ParentID)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		forums = append(forums, f)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return forums, nil
+}
+
+// SetParent nests the forum into parent, or makes it a top level forum
+// when parent is nil.
+func (r *Repository) SetParent(f *models.Forum, parent *models.Forum) error {
+	f.ParentID, f.Parent = 0, ""
+	if parent != nil {
+		f.ParentID, f.Parent = parent.ID, parent.Slug
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Parent changes are serialized, so two moves can't each pass the check
+	// below and close a cycle together.
+	if _, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext('forums.parent'))"); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.QueryRow(
+		`WITH RECURSIVE above (id) AS (
+				SELECT $1::bigint
+				UNION
+				SELECT f.parent FROM forums f JOIN above a ON f.id = a.id WHERE f.parent IS NOT NULL
+			)
+			UPDATE forums SET parent = NULLIF($1, 0)
+				WHERE id = $2 AND id NOT IN (SELECT id FROM above)
+				RETURNING modified`,
+		f.ParentID,
+		f.ID,
+	).Scan(&f.Modified)
+	if err == sql.ErrNoRows {
+		_ = tx.Rollback()
+		return errors.New(forum.FORUM_CYCLE)
+	} else if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *Repository) GetThreads(slug string, params *models.ListParameters) ([]*models.Thread, error){
 	var err error
 	var rows *sql.Rows
@@ -117,15 +206,18 @@ func (r *Repository) GetThreads(slug string, params *models.ListParameters) ([]*
 	}
 
 	rows, err = r.db.Query(
-		`SELECT id, forum, author, created, message, title, slug, votes 
+		`SELECT id, forum, author, created, message, title, slug, votes, upvotes, downvotes, version, COALESCE(moved_to, 0)
 						FROM threads
-						WHERE LOWER(forum) = LOWER($1) AND 
-						      (NOT $5 OR (NOT $3 AND created >= $2) OR ($3 AND created <= $2))
+						WHERE (LOWER(forum) = LOWER($1) OR LOWER(forum) = ANY($8::varchar[])) AND 
+						      (NOT $5 OR (NOT $3 AND created >= $2) OR ($3 AND created <= $2)) AND
+						      (COALESCE(cardinality($6::varchar[]), 0) = 0 OR
+						       (SELECT COUNT(*) FROM thread_tags tt WHERE tt.thread = threads.id AND tt.tag = ANY($6::varchar[])) >=
+						       CASE WHEN $7 THEN 1 ELSE cardinality($6::varchar[]) END)
 						ORDER BY
 							CASE WHEN $3 THEN created END DESC,
 							CASE WHEN NOT $3 THEN created END ASC
 						LIMIT CASE WHEN $4 > 0 THEN $4 END;`,
-		slug, t, params.Desc, params.Limit, sinceSet)
+		slug, t, params.Desc, params.Limit, sinceSet, pq.Array(params.Tags), params.TagMode == "any", pq.Array(params.Forums))
 
 
 	if err != nil {
@@ -134,7 +226,7 @@ func (r *Repository) GetThreads(slug string, params *models.ListParameters) ([]*
 
 	for rows.Next() {
 		t := new(models.Thread)
-		err := rows.Scan(&t.ID, &t.Forum, &t.Author, &t.Created, &t.Message, &t.Title, &t.Slug, &t.Votes)
+		err := rows.Scan(&t.ID, &t.Forum, &t.Author, &t.Created, &t.Message, &t.Title, &t.Slug, &t.Votes, &t.Upvotes, &t.Downvotes, &t.Version, &t.MovedTo)
 		if err != nil {
 			return nil, err
 		}
@@ -151,7 +243,7 @@ func (r *Repository) GetThreads(slug string, params *models.ListParameters) ([]*
 func (r *Repository) FindThread(id int64) (*models.Thread, error) {
 	t := new(models.Thread)
 	if err := r.db.QueryRow(
-		"SELECT id, forum, author, created, message, title, slug, votes FROM threads WHERE id = $1",
+		"SELECT id, forum, author, created, message, title, slug, votes, upvotes, downvotes, version, modified, COALESCE(moved_to, 0) FROM threads WHERE id = $1",
 		id,
 	).Scan(
 		&t.ID,
@@ -162,16 +254,65 @@ func (r *Repository) FindThread(id int64) (*models.Thread, error) {
 		&t.Title,
 		&t.Slug,
 		&t.Votes,
+		&t.Upvotes,
+		&t.Downvotes,
+		&t.Version,
+		&t.Modified,
+		&t.MovedTo,
 	); err != nil {
 		return nil, err
 	}
 	return t, nil
 }
 
+// FindThreads loads several threads at once; ids without a thread are
+// missing from the result.
+func (r *Repository) FindThreads(ids []int64) (map[int64]*models.Thread, error) {
+	threads := make(map[int64]*models.Thread, len(ids))
+	if len(ids) == 0 {
+		return threads, nil
+	}
+
+	rows, err := r.db.Query(
+		"SELECT id, forum, author, created, message, title, slug, votes, upvotes, downvotes, version, modified, COALESCE(moved_to, 0) FROM threads " +
+			"WHERE id = ANY($1::bigint[])",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		t := new(models.Thread)
+		if err := rows.Scan(&t.ID, &t.Forum, &t.Author, &t.Created, &t.Message, &t.Title, &t.Slug, &t.Votes, &t.Upvotes,
+			&t.Downvotes, &t.Version, &t.Modified, &t.MovedTo); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		threads[t.ID] = t
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
+
+// FindThreadBySlug also accepts slugs the thread had before a rename.
 func (r *Repository) FindThreadBySlug(slug string) (*models.Thread, error) {
+	t, err := r.findThreadBySlug(slug)
+	if err == sql.ErrNoRows {
+		if id, aliasErr := r.findAlias("thread", slug); aliasErr == nil {
+			return r.FindThread(id)
+		}
+	}
+	return t, err
+}
+
+func (r *Repository) findThreadBySlug(slug string) (*models.Thread, error) {
 	t := new(models.Thread)
 	if err := r.db.QueryRow(
-		"SELECT id, forum, author, created, message, title, slug, votes FROM threads " +
+		"SELECT id, forum, author, created, message, title, slug, votes, upvotes, downvotes, version, modified, COALESCE(moved_to, 0) FROM threads " +
 			"WHERE LOWER(slug) = LOWER($1)",
 		slug,
 	).Scan(
@@ -183,39 +324,602 @@ func (r *Repository) FindThreadBySlug(slug string) (*models.Thread, error) {
 		&t.Title,
 		&t.Slug,
 		&t.Votes,
+		&t.Upvotes,
+		&t.Downvotes,
+		&t.Version,
+		&t.Modified,
+		&t.MovedTo,
 	); err != nil {
 		return nil, err
 	}
 	return t, nil
 }
 
-func (r *Repository) UpdateThread(thread *models.Thread) error {
+func (r *Repository) findAlias(kind string, slug string) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(
+		"SELECT target FROM slug_aliases WHERE kind = $1 AND LOWER(slug) = LOWER($2)",
+		kind,
+		slug,
+	).Scan(&id)
+	return id, err
+}
+
+// saveAlias keeps the old slug pointing at target. An alias equal to the
+// new slug is dropped, since that slug is canonical again.
+func saveAlias(tx *sql.Tx, kind string, old string, slug string, target int64) error {
+	if _, err := tx.Exec(
+		"DELETE FROM slug_aliases WHERE kind = $1 AND LOWER(slug) = LOWER($2)",
+		kind,
+		slug,
+	); err != nil {
+		return err
+	}
+
+	if old == "" || strings.ToLower(old) == strings.ToLower(slug) {
+		return nil
+	}
+
+	_, err := tx.Exec(
+		"INSERT INTO slug_aliases (kind, slug, target) VALUES ($1, $2, $3) " +
+			"ON CONFLICT (kind, LOWER(slug)) DO UPDATE SET target = EXCLUDED.target",
+		kind,
+		old,
+		target,
+	)
+	return err
+}
+
+// RenameForum changes the forum slug together with the copies kept in
+// threads and posts. Moving the threads fires on_thread_count for every
+// row, so the thread counter is restored to its value from before the
+// rename.
+func (r *Repository) RenameForum(f *models.Forum, slug string) error {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
 
-	stmt, err := tx.Prepare("UPDATE threads SET votes = $1, title = $2, message = $3 WHERE id = $4")
+	old := f.Slug
+	var threads int64
+	err = tx.QueryRow(
+		"UPDATE forums SET slug = $1 WHERE id = $2 RETURNING slug, threads, modified",
+		slug,
+		f.ID,
+	).Scan(&f.Slug, &threads, &f.Modified)
 	if err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 
-	defer stmt.Close()
+	if _, err = tx.Exec("UPDATE threads SET forum = $1 WHERE LOWER(forum) = LOWER($2)", slug, old); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 
-	_, err = stmt.Exec(thread.Votes, thread.Title, thread.Message, thread.ID)
+	if _, err = tx.Exec("UPDATE posts SET forum = $1 WHERE LOWER(forum) = LOWER($2)", slug, old); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec("UPDATE moderation_queue SET forum = $1 WHERE LOWER(forum) = LOWER($2)", slug, old); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec("UPDATE forums SET threads = $1 WHERE id = $2", threads, f.ID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = saveAlias(tx, "forum", old, slug, f.ID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) RenameThread(thread *models.Thread, slug string) error {
+	tx, err := r.db.Begin()
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
-	err = tx.Commit()
+	old := thread.Slug
+	err = tx.QueryRow(
+		"UPDATE threads SET slug = $1, version = version + 1 WHERE id = $2 RETURNING slug, version, modified",
+		slug,
+		thread.ID,
+	).Scan(&thread.Slug, &thread.Version, &thread.Modified)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = saveAlias(tx, "thread", old, slug, thread.ID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MoveThread reassigns the thread and its posts to another forum and leaves
+// a stub pointing at the thread in the old one. Thread counters are kept by
+// the on_thread_count trigger, which ignores stubs.
+func (r *Repository) MoveThread(thread *models.Thread, to *models.Forum, moderator string) (*models.Thread, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	from := thread.Forum
+	err = tx.QueryRow(
+		"UPDATE threads SET forum = $1, version = version + 1 WHERE id = $2 RETURNING forum, version, modified",
+		to.Slug,
+		thread.ID,
+	).Scan(&thread.Forum, &thread.Version, &thread.Modified)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	res, err := tx.Exec("UPDATE posts SET forum = $1 WHERE thread = $2", to.Slug, thread.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	moved, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if _, err = tx.Exec(`
+		UPDATE forums
+			SET posts = posts + CASE WHEN LOWER(slug) = LOWER($2) THEN $1 ELSE -$1 END
+			WHERE LOWER(slug) IN (LOWER($2), LOWER($3))
+		`,
+		moved,
+		to.Slug,
+		from,
+	); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	stub := &models.Thread{
+		Forum:		from,
+		Author:		thread.Author,
+		Created:	thread.Created,
+		Title:		thread.Title,
+		MovedTo:	thread.ID,
+	}
+	err = tx.QueryRow("INSERT INTO threads (forum, author, created, message, title, slug, votes, moved_to) " +
+		"VALUES ($1, $2, $3, '', $4, '', 0, $5) RETURNING id",
+		stub.Forum,
+		stub.Author,
+		stub.Created,
+		stub.Title,
+		stub.MovedTo,
+	).Scan(&stub.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	// Authors of the thread now take part in the new forum, and those who
+	// have nothing left in the old one are no longer its users.
+	if _, err = tx.Exec(`
+		INSERT INTO forum_users (user_id, forum_id)
+			SELECT u.id, $2
+				FROM users u
+				WHERE LOWER(u.nickname) IN (
+					SELECT LOWER(author) FROM threads WHERE id = $1
+					UNION
+					SELECT LOWER(author) FROM posts WHERE thread = $1
+				) AND NOT EXISTS (SELECT 1 FROM forum_users fu WHERE fu.user_id = u.id AND fu.forum_id = $2)
+		`,
+		thread.ID,
+		to.ID,
+	); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if _, err = tx.Exec(`
+		DELETE FROM forum_users fu
+			USING users u, forums f
+			WHERE fu.user_id = u.id AND fu.forum_id = f.id AND LOWER(f.slug) = LOWER($1) AND
+				NOT EXISTS (SELECT 1 FROM threads t WHERE LOWER(t.forum) = LOWER($1) AND LOWER(t.author) = LOWER(u.nickname)) AND
+				NOT EXISTS (SELECT 1 FROM posts p WHERE LOWER(p.forum) = LOWER($1) AND LOWER(p.author) = LOWER(u.nickname))
+		`,
+		from,
+	); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if err = audit(tx, &models.ThreadAudit{
+		Action:		"move",
+		Moderator:	moderator,
+		Thread:		thread.ID,
+		Forum:		to.Slug,
+		Posts:		moved,
+	}); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return stub, nil
+}
+
+// MergeThreads moves every post of from into the other thread. The opening
+// message of from becomes a new root post and the old roots are hung under
+// it, so every moved path gets that post prepended and the merged tree keeps
+// its shape. created is left alone and flat order stays chronological.
+// from is left behind as a stub pointing at into.
+func (r *Repository) MergeThreads(from *models.Thread, into *models.Thread, moderator string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var root int64
+	err = tx.QueryRow(`
+		INSERT INTO posts (id, parent, thread, forum, author, created, message, path)
+			VALUES (nextval('posts_id_seq'::regclass), 0, $1, $2, $3, $4, $5,
+				ARRAY[currval(pg_get_serial_sequence('posts', 'id'))::bigint])
+			RETURNING id
+		`,
+		into.ID,
+		into.Forum,
+		from.Author,
+		from.Created,
+		from.Message,
+	).Scan(&root)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE posts
+			SET thread = $1, forum = $2, path = ARRAY[$3::bigint] || path,
+				parent = CASE WHEN parent = 0 THEN $3 ELSE parent END
+			WHERE thread = $4
+		`,
+		into.ID,
+		into.Forum,
+		root,
+		from.ID,
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	moved, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec("UPDATE forums SET posts = posts - $1 WHERE LOWER(slug) = LOWER($2)", moved, from.Forum); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec("UPDATE forums SET posts = posts + $1 WHERE LOWER(slug) = LOWER($2)", moved + 1, into.Forum); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec(`
+		INSERT INTO forum_users (user_id, forum_id)
+			SELECT DISTINCT u.id, f.id
+				FROM posts p
+				JOIN users u ON LOWER(u.nickname) = LOWER(p.author)
+				JOIN forums f ON LOWER(f.slug) = LOWER(p.forum)
+				WHERE p.thread = $1 AND
+					NOT EXISTS (SELECT 1 FROM forum_users fu WHERE fu.user_id = u.id AND fu.forum_id = f.id)
+		`,
+		into.ID,
+	); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec("UPDATE threads SET moved_to = $1 WHERE id = $2", into.ID, from.ID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = audit(tx, &models.ThreadAudit{
+		Action:		"merge",
+		Moderator:	moderator,
+		Thread:		from.ID,
+		Target:		into.ID,
+		Posts:		moved,
+	}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SplitThread creates thread and moves the subtree rooted at post into it.
+// The subtree is found by path prefix, and the prefix above post is cut off
+// so that post becomes a root.
+func (r *Repository) SplitThread(post *models.Post, from *models.Thread, thread *models.Thread, moderator string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow("INSERT INTO threads (forum, author, created, message, title, slug, votes) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, 0) RETURNING id, version, modified",
+		thread.Forum,
+		thread.Author,
+		thread.Created,
+		thread.Message,
+		thread.Title,
+		thread.Slug,
+	).Scan(&thread.ID, &thread.Version, &thread.Modified)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	res, err := tx.Exec(`
+		WITH root AS (SELECT path FROM posts WHERE id = $1)
+		UPDATE posts
+			SET thread = $2, path = posts.path[array_length(root.path, 1):],
+				parent = CASE WHEN posts.id = $1 THEN 0 ELSE posts.parent END
+			FROM root
+			WHERE posts.thread = $3 AND posts.path[1:array_length(root.path, 1)] = root.path
+		`,
+		post.ID,
+		thread.ID,
+		from.ID,
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	moved, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = audit(tx, &models.ThreadAudit{
+		Action:		"split",
+		Moderator:	moderator,
+		Thread:		from.ID,
+		Target:		thread.ID,
+		Posts:		moved,
+	}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) ThreadAudit(thread int64) ([]*models.ThreadAudit, error) {
+	rows, err := r.db.Query(
+		`SELECT id, action, COALESCE(moderator, ''), thread, COALESCE(target, 0), COALESCE(forum, ''), posts, created
+				FROM thread_audit
+				WHERE thread = $1 OR target = $1
+				ORDER BY id`,
+		thread,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*models.ThreadAudit, 0)
+	for rows.Next() {
+		a := new(models.ThreadAudit)
+		if err := rows.Scan(&a.ID, &a.Action, &a.Moderator, &a.Thread, &a.Target, &a.Forum, &a.Posts, &a.Created); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		entries = append(entries, a)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func audit(tx *sql.Tx, a *models.ThreadAudit) error {
+	return tx.QueryRow(
+		"INSERT INTO thread_audit (action, moderator, thread, target, forum, posts) " +
+			"VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, ''), $6) RETURNING id, created",
+		a.Action,
+		a.Moderator,
+		a.Thread,
+		a.Target,
+		a.Forum,
+		a.Posts,
+	).Scan(&a.ID, &a.Created)
+}
+
+// UpdateThread writes the title and message that are set and, unless
+// version is 0, only if the thread is still at that version. votes are
+// maintained by triggers and never written here.
+func (r *Repository) UpdateThread(thread *models.Thread) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
 
+	err = tx.QueryRow(
+		"UPDATE threads SET title = COALESCE(NULLIF($1, ''), title), message = COALESCE(NULLIF($2, ''), message), " +
+			"version = version + 1 WHERE id = $3 AND ($4::bigint = 0 OR version = $4) RETURNING version, modified",
+		thread.Title,
+		thread.Message,
+		thread.ID,
+		thread.Version,
+	).Scan(&thread.Version, &thread.Modified)
+	if err == sql.ErrNoRows {
+		_ = tx.Rollback()
+		return errors.New(forum.VERSION_CONFLICT)
+	} else if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if thread.Tags != nil {
+		if _, err = tx.Exec("DELETE FROM thread_tags WHERE thread = $1", thread.ID); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+
+		if err = saveTags(tx, thread); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func saveTags(tx *sql.Tx, thread *models.Thread) error {
+	for _, tag := range thread.Tags {
+		if _, err := tx.Exec(
+			"INSERT INTO thread_tags (thread, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			thread.ID,
+			tag,
+		); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (r *Repository) FindTags(threads []int64) (map[int64][]string, error) {
+	tags := make(map[int64][]string, len(threads))
+	if len(threads) == 0 {
+		return tags, nil
+	}
+
+	rows, err := r.db.Query(
+		"SELECT thread, tag FROM thread_tags WHERE thread = ANY($1::bigint[]) ORDER BY thread, tag",
+		pq.Array(threads),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var thread int64
+		var tag string
+		if err := rows.Scan(&thread, &tag); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		tags[thread] = append(tags[thread], tag)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *Repository) ForumTags(slug string) ([]*models.TagCount, error) {
+	rows, err := r.db.Query(
+		`SELECT tt.tag, COUNT(*)
+				FROM thread_tags tt
+				JOIN threads t ON t.id = tt.thread
+				WHERE LOWER(t.forum) = LOWER($1) AND t.moved_to IS NULL
+				GROUP BY tt.tag
+				ORDER BY COUNT(*) DESC, tt.tag`,
+		slug,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]*models.TagCount, 0)
+	for rows.Next() {
+		c := new(models.TagCount)
+		if err := rows.Scan(&c.Tag, &c.Threads); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// MarkRead moves the user's read marker of the thread to post, or to the
+// last post when post is 0. The marker never moves backwards.
+func (r *Repository) MarkRead(nickname string, thread *models.Thread, post int64) (*models.ReadMarker, error) {
+	m := &models.ReadMarker{Nickname: nickname, Thread: thread.ID}
+	if err := r.db.QueryRow(
+		`INSERT INTO thread_reads (nickname, thread, last_post)
+				VALUES ($1, $2, CASE WHEN $3 > 0 THEN $3 ELSE (SELECT COALESCE(MAX(id), 0) FROM posts WHERE thread = $2) END)
+				ON CONFLICT (LOWER(nickname), thread)
+					DO UPDATE SET last_post = GREATEST(thread_reads.last_post, EXCLUDED.last_post)
+				RETURNING last_post,
+					(SELECT COUNT(*) FROM posts WHERE thread = $2 AND id > thread_reads.last_post)`,
+		nickname,
+		thread.ID,
+		post,
+	).Scan(&m.Post, &m.Unread); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *Repository) UnreadCounts(nickname string, threads []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(threads))
+	if len(threads) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.db.Query(
+		`SELECT t.id, (SELECT COUNT(*) FROM posts p WHERE p.thread = t.id AND p.id > COALESCE(tr.last_post, 0))
+				FROM unnest($2::bigint[]) AS t(id)
+				LEFT JOIN thread_reads tr ON tr.thread = t.id AND LOWER(tr.nickname) = LOWER($1)`,
+		nickname,
+		pq.Array(threads),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var id, n int64
+		if err := rows.Scan(&id, &n); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		counts[id] = n
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
 func (r * Repository) CreatePosts(posts []*models.Post, thread *models.Thread) error {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -286,7 +990,7 @@ func (r * Repository) CreatePosts(posts []*models.Post, thread *models.Thread) e
 	sqlStr = strings.TrimSuffix(sqlStr, ",")
 
 	sqlStr += `
-		RETURNING id, parent, thread, forum, author, created, message, isEdited 
+		RETURNING id, parent, thread, forum, author, created, message, isEdited, version
 	`
 
 	sqlStr = ReplaceSQL(sqlStr, "?")
@@ -307,6 +1011,7 @@ func (r * Repository) CreatePosts(posts []*models.Post, thread *models.Thread) e
 				&(posts)[i].Created,
 				&(posts)[i].Message,
 				&(posts)[i].IsEdited,
+				&(posts)[i].Version,
 			)
 			i += 1
 
@@ -318,6 +1023,36 @@ func (r * Repository) CreatePosts(posts []*models.Post, thread *models.Thread) e
 		}
 	}
 
+	for _, post := range posts {
+		for _, quote := range post.Quotes {
+			_, err = tx.Exec(
+				"INSERT INTO post_quotes (post, quoted) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+				post.ID,
+				quote.Post,
+			)
+			if err != nil {
+				_ = tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	authors := make([]string, 0, len(posts))
+	for _, post := range posts {
+		authors = append(authors, strings.ToLower(post.Author))
+	}
+
+	// Publishing in a thread makes the author's draft for it obsolete.
+	_, err = tx.Exec(
+		"DELETE FROM drafts WHERE thread = $1 AND LOWER(nickname) = ANY($2::text[])",
+		thread.ID,
+		pq.Array(authors),
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
 	_, err = tx.Exec(`
 		UPDATE forums
 			SET posts = posts + $1
@@ -342,7 +1077,7 @@ func (r * Repository) CreatePosts(posts []*models.Post, thread *models.Thread) e
 func (r *Repository) FindPost(id int64) (*models.Post, error) {
 	p := new(models.Post)
 	if err := r.db.QueryRow(
-		"SELECT id, author, created, forum, isEdited, message, parent, thread FROM posts WHERE id = $1",
+		"SELECT id, author, created, forum, isEdited, message, parent, thread, votes, version, modified FROM posts WHERE id = $1",
 		id,
 	).Scan(
 		&p.ID,
@@ -353,37 +1088,137 @@ func (r *Repository) FindPost(id int64) (*models.Post, error) {
 		&p.Message,
 		&p.Parent,
 		&p.Thread,
+		&p.Votes,
+		&p.Version,
+		&p.Modified,
 	); err != nil {
 		return nil, err
 	}
 	return p, nil
 }
 
-func (r *Repository) UpdatePost(post *models.Post) error {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return err
+// FindPosts loads several posts at once; ids without a post are missing
+// from the result.
+func (r *Repository) FindPosts(ids []int64) (map[int64]*models.Post, error) {
+	posts := make(map[int64]*models.Post, len(ids))
+	if len(ids) == 0 {
+		return posts, nil
 	}
 
-	stmt, err := tx.Prepare("UPDATE posts SET message = $1, isEdited = $2 WHERE id = $3 RETURNING id")
+	rows, err := r.db.Query(
+		"SELECT id, author, created, forum, isEdited, message, parent, thread, votes, version, modified FROM posts " +
+			"WHERE id = ANY($1::bigint[])",
+		pq.Array(ids),
+	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer stmt.Close()
+	for rows.Next() {
+		p := new(models.Post)
+		if err := rows.Scan(&p.ID, &p.Author, &p.Created, &p.Forum, &p.IsEdited, &p.Message, &p.Parent, &p.Thread,
+			&p.Votes, &p.Version, &p.Modified); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		posts[p.ID] = p
+	}
 
-	_, err = stmt.Exec(post.Message, post.IsEdited, post.ID)
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *Repository) FindQuotes(posts []int64) (map[int64][]*models.Quote, error) {
+	quotes := make(map[int64][]*models.Quote, len(posts))
+	if len(posts) == 0 {
+		return quotes, nil
+	}
+
+	rows, err := r.db.Query(
+		`SELECT q.post, p.id, p.author, p.thread
+				FROM post_quotes q
+				JOIN posts p ON p.id = q.quoted
+				WHERE q.post = ANY($1::bigint[])
+				ORDER BY q.post, q.quoted`,
+		pq.Array(posts),
+	)
 	if err != nil {
-		tx.Rollback()
-		return err
+		return nil, err
 	}
 
-	err = tx.Commit()
+	for rows.Next() {
+		var post int64
+		q := new(models.Quote)
+		if err := rows.Scan(&post, &q.Post, &q.Author, &q.Thread); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		quotes[post] = append(quotes[post], q)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return quotes, nil
+}
+
+func (r *Repository) FindQuotingPosts(id int64) ([]*models.Post, error) {
+	rows, err := r.db.Query(
+		`SELECT p.id, p.author, p.created, p.forum, p.isEdited, p.message, p.parent, p.thread, p.votes, p.version, p.modified
+				FROM post_quotes q
+				JOIN posts p ON p.id = q.post
+				WHERE q.quoted = $1
+				ORDER BY p.id`,
+		id,
+	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	posts := make([]*models.Post, 0)
+	for rows.Next() {
+		p := new(models.Post)
+		err := rows.Scan(
+			&p.ID,
+			&p.Author,
+			&p.Created,
+			&p.Forum,
+			&p.IsEdited,
+			&p.Message,
+			&p.Parent,
+			&p.Thread,
+			&p.Votes,
+			&p.Version,
+			&p.Modified,
+		)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *Repository) UpdatePost(post *models.Post) error {
+	err := r.db.QueryRow(
+		"UPDATE posts SET message = $1, isEdited = $2, version = version + 1 " +
+			"WHERE id = $3 AND version = $4 RETURNING version, modified",
+		post.Message,
+		post.IsEdited,
+		post.ID,
+		post.Version,
+	).Scan(&post.Version, &post.Modified)
+	if err == sql.ErrNoRows {
+		return errors.New(forum.VERSION_CONFLICT)
+	}
+	return err
 }
 
 func (r *Repository) CreateVote(vote *models.Vote, thread *models.Thread) (int64, error) {
@@ -405,9 +1240,11 @@ func (r *Repository) CreateVote(vote *models.Vote, thread *models.Thread) (int64
 	}
 
 	var numVotes int64
-	rowT := tx.QueryRow("SELECT votes FROM threads WHERE id = $1", thread.ID)
+	rowT := tx.QueryRow("SELECT votes, upvotes, downvotes FROM threads WHERE id = $1", thread.ID)
 	err = rowT.Scan(
 		&numVotes,
+		&thread.Upvotes,
+		&thread.Downvotes,
 	)
 
 	if err != nil {
@@ -423,10 +1260,77 @@ func (r *Repository) CreateVote(vote *models.Vote, thread *models.Thread) (int64
 	return numVotes, nil
 }
 
+func (r *Repository) FindVote(nickname string, thread *models.Thread) (*models.Vote, error) {
+	v := new(models.Vote)
+	if err := r.db.QueryRow(
+		"SELECT id, nickname, vote FROM votes WHERE LOWER(nickname) = LOWER($1) AND thread = $2",
+		nickname,
+		thread.ID,
+	).Scan(
+		&v.ID,
+		&v.Nickname,
+		&v.Voice,
+	); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (r *Repository) DeleteVote(nickname string, thread *models.Thread) error {
+	res, err := r.db.Exec(
+		"DELETE FROM votes WHERE LOWER(nickname) = LOWER($1) AND thread = $2",
+		nickname,
+		thread.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *Repository) CreatePostVote(vote *models.Vote, post *models.Post) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec("INSERT INTO post_votes(nickname, vote, post) VALUES ($1, $2, $3) " +
+		"ON CONFLICT (LOWER(nickname), post) DO UPDATE SET vote = $2",
+		vote.Nickname, vote.Voice, post.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	var numVotes int64
+	if err = tx.QueryRow("SELECT votes FROM posts WHERE id = $1", post.ID).Scan(&numVotes); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return numVotes, nil
+}
+
 func (r *Repository) GetPosts(thread *models.Thread, params *models.ListParameters) ([]*models.Post, error) {
 	posts := make([]*models.Post, 0)
 
 	var query string
+	args := []interface{}{thread.ID}
+	if params.Since != "" {
+		args = append(args, params.Since)
+	}
 
 	conditionSign := ">"
 	if params.Desc == true {
@@ -439,40 +1343,51 @@ func (r *Repository) GetPosts(thread *models.Thread, params *models.ListParamete
 	}
 
 	if params.Sort == "flat" {
-		query = "SELECT id, parent, thread, forum, author, created, message, isedited, path FROM posts WHERE thread = $1 "
+		query = "SELECT id, parent, thread, forum, author, created, message, isedited, path, votes, version, modified FROM posts WHERE thread = $1 "
+		// The cursor follows the order, not the id alone: the root post a
+		// merge creates gets a new id but the created time of the old thread.
 		if params.Since != "" {
-			query += fmt.Sprintf(" AND id %s %s ", conditionSign, params.Since)
+			query += fmt.Sprintf(" AND (created, id) %s (SELECT created, id FROM posts WHERE id = $2) ", conditionSign)
 		}
 		query += fmt.Sprintf(" ORDER BY created %s, id %s LIMIT %d", order, order, params.Limit)
+	} else if params.Sort == "top" {
+		// Highest score first, ties keep the flat order. The cursor is the
+		// score the since post has now, then its place in the flat order.
+		query = "SELECT id, parent, thread, forum, author, created, message, isedited, path, votes, version, modified FROM posts WHERE thread = $1 "
+		if params.Since != "" {
+			query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM posts s WHERE s.id = $2 AND (posts.votes < s.votes OR " +
+				"(posts.votes = s.votes AND (posts.created, posts.id) %s (s.created, s.id)))) ", conditionSign)
+		}
+		query += fmt.Sprintf(" ORDER BY votes DESC, created %s, id %s LIMIT %d", order, order, params.Limit)
 	} else if params.Sort == "tree" {
 		orderString := fmt.Sprintf(" ORDER BY path[1] %s, path %s ", order, order)
-		query = "SELECT id, parent, thread, forum, author, created, message, isedited, path " +
+		query = "SELECT id, parent, thread, forum, author, created, message, isedited, path, votes, version, modified " +
 			"FROM posts " +
 			"WHERE thread = $1 "
 		if params.Since != "" {
-			query += fmt.Sprintf(" AND path %s (SELECT path FROM posts WHERE id = %s) ", conditionSign, params.Since)
+			query += fmt.Sprintf(" AND path %s (SELECT path FROM posts WHERE id = $2) ", conditionSign)
 		}
 		query += orderString
 		query += fmt.Sprintf("LIMIT %d", params.Limit)
 	}else if params.Sort == "parent_tree" {
-		query = "SELECT id, parent, thread, forum, author, created, message, isedited, path " +
+		query = "SELECT id, parent, thread, forum, author, created, message, isedited, path, votes, version, modified " +
 			"FROM posts " +
 			"WHERE thread = $1 AND path && (SELECT ARRAY (select id from posts WHERE thread = $1 AND parent = 0 "
 		if params.Since != "" {
-			query += fmt.Sprintf(" AND path %s (SELECT path[1:1] FROM posts WHERE id = %s) ", conditionSign, params.Since)
+			query += fmt.Sprintf(" AND path %s (SELECT path[1:1] FROM posts WHERE id = $2) ", conditionSign)
 		}
 		query += fmt.Sprintf("ORDER BY path[1] %s, path LIMIT %d)) ", order, params.Limit)
 		query += fmt.Sprintf("ORDER BY path[1] %s, path ", order)
 	}
 
-	rows, err := r.db.Query(query, thread.ID)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	for rows.Next() {
 		p := models.Post{}
-		err := rows.Scan(&p.ID, &p.Parent, &p.Thread, &p.Forum, &p.Author, &p.Created, &p.Message, &p.IsEdited, pq.Array(&p.Path))
+		err := rows.Scan(&p.ID, &p.Parent, &p.Thread, &p.Forum, &p.Author, &p.Created, &p.Message, &p.IsEdited, pq.Array(&p.Path), &p.Votes, &p.Version, &p.Modified)
 		if err != nil {
 			return nil, err
 		}
@@ -526,7 +1441,7 @@ func (r *Repository) GetUsers(id int64, params models.ListParameters) ([]*models
 func (r *Repository) FindUser(nickname string) (*models.User, error) {
 	u := new(models.User)
 	if err := r.db.QueryRow(
-		"SELECT id, email, about, fullname, nickname FROM users WHERE LOWER(nickname) = LOWER($1)",
+		"SELECT id, email, about, fullname, nickname, digest FROM users WHERE LOWER(nickname) = LOWER($1)",
 		nickname,
 	).Scan(
 		&u.ID,
@@ -534,6 +1449,7 @@ func (r *Repository) FindUser(nickname string) (*models.User, error) {
 		&u.About,
 		&u.FullName,
 		&u.Nickname,
+		&u.Digest,
 	); err != nil {
 		return nil, err
 	}
@@ -7,23 +7,54 @@ const (
 	NOT_FOUND_ERR = "Can't find post author by nickname: "
 	THREAD_NOT_FOUND = "Can't find such thread"
 	PARENT_POST_CONFLICT = "Parent post was created in another thread"
+	POST_THREAD_CONFLICT = "Post was created in another thread"
 	THREAD_CONFLICT = "Such thread already exists"
 	FORUM_CONFLICT = "Such forum already exists"
 	VOTE_CONFLICT = "Such user already voted"
 	POST_NOT_FOUND = "Can't find such post"
 	USER_NOT_FOUND = "Can't find user by nickname: "
 	WRONG_INPUT = "Wrong input"
+	VERSION_CONFLICT = "Resource was modified concurrently"
+	VOTE_NOT_FOUND = "Can't find vote of user: "
+	QUOTE_NOT_FOUND = "Can't find quoted post: "
+	THREAD_MOVED = "Thread was moved to another forum"
+	FORBIDDEN = "Only moderators can do this"
+	WRONG_TAGS = "Too many tags or tag is too long"
+	PARENT_NOT_FOUND = "Can't find parent forum by slug: "
+	FORUM_CYCLE = "Forum can't be nested into itself"
+	REJECTED = "Rejected by content filter "
+	WRONG_FILTERS = "Wrong filter settings: "
+	ITEM_NOT_FOUND = "Can't find such moderation item"
+	ITEM_RESOLVED = "Moderation item was already resolved"
+	UNAUTHORIZED = "Log in to keep read markers"
+	MARKER_FORBIDDEN = "Read markers can only be set by their owner"
+)
+
+const (
+	MAX_TAGS = 10
+	MAX_TAG_LENGTH = 32
 )
 
 type Usecase interface {
 	CreateForum(forum *models.Forum) (*models.Forum, error)
 	GetForum(slug string) (*models.Forum, error)
+	GetForumTree() ([]*models.Forum, error)
+	SetParent(slug string, parent string, moderator string) (*models.Forum, error)
+	RenameForum(slug string, newSlug string, moderator string) (*models.Forum, error)
 	GetUsers(slug string, params models.ListParameters) ([]*models.User, error)
 
 	CreateThread(newThread *models.Thread) (*models.Thread, error)
 	GetThreads(slug string, params *models.ListParameters) ([]*models.Thread, error)
+	GetTags(slug string) ([]*models.TagCount, error)
 	GetThread(currThread string) (*models.Thread, error)
+	GetThreadDetails(currThread string) (*models.Thread, error)
 	UpdateThread(currThread string, thread *models.Thread) (*models.Thread, error)
+	RenameThread(currThread string, newSlug string, moderator string) (*models.Thread, error)
+	MoveThread(currThread string, forumSlug string, moderator string) (*models.Thread, error)
+	MergeThreads(currThread string, into string, moderator string) (*models.Thread, error)
+	SplitThread(post int64, thread *models.Thread, moderator string) (*models.Thread, error)
+	GetThreadAudit(currThread string) ([]*models.ThreadAudit, error)
+	MarkRead(currThread string, nickname string, post int64) (*models.ReadMarker, error)
 
 	CreatePosts(currForum string, posts []*models.Post) error
 	GetPosts(currThread string, params *models.ListParameters) ([]*models.Post, error)
@@ -31,5 +62,16 @@ type Usecase interface {
 	FindPostDetail(id int64, related string) (*models.Combine, error)
 	UpdatePost(post *models.Post) (*models.Post, error)
 
+	RenderThreads(threads ...*models.Thread)
+	RenderPosts(posts ...*models.Post)
+
+	GetFilters(slug string, moderator string) (*models.FilterSettings, error)
+	SetFilters(slug string, moderator string, s *models.FilterSettings) (*models.FilterSettings, error)
+	GetModerationQueue(slug string, moderator string, params *models.ListParameters) ([]*models.ModerationItem, error)
+	Moderate(id int64, moderator string, approve bool) (*models.ModerationItem, error)
+
 	CreateVote(vote *models.Vote) (*models.Thread, error)
+	GetVote(currThread string, nickname string) (*models.Vote, error)
+	DeleteVote(currThread string, nickname string) (*models.Thread, error)
+	CreatePostVote(vote *models.Vote) (*models.Post, error)
 }
@@ -0,0 +1,219 @@
+package forum_ucase
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/filter"
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+// screen runs the forum filters over new content. Rejections come back as
+// an error, holds and flags as the verdict.
+func (u *ForumUcase) screen(f *models.Forum, author string, title string, message string) (*models.FilterVerdict, error) {
+	s, err := u.filterSettings(f.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	verdict, err := u.pipeline.Run(s, &filter.Content{
+		Author:		author,
+		Title:		title,
+		Message:	message,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "pipeline.Run()")
+	}
+
+	if verdict != nil && verdict.Action == filter.ACTION_REJECT {
+		return nil, errors.New(forum.REJECTED + verdict.Filter + ": " + verdict.Reason)
+	}
+	return verdict, nil
+}
+
+// screenPosts splits a batch into posts to publish now and posts held for
+// moderation.
+func (u *ForumUcase) screenPosts(t *models.Thread, posts []*models.Post, screen bool) ([]*models.Post, map[*models.Post]bool, error) {
+	var f *models.Forum
+	if screen {
+		var err error
+		if f, err = u.repository.FindBySlug(t.Forum); err != nil {
+			return nil, nil, errors.Wrap(errors.New(forum.NOT_FOUND), "forumRep.FindBySlug()")
+		}
+	}
+
+	publish := make([]*models.Post, 0, len(posts))
+	held := make(map[*models.Post]bool)
+	for _, post := range posts {
+		post.Moderation = nil
+		if screen {
+			verdict, err := u.screen(f, post.Author, "", post.Message)
+			if err != nil {
+				return nil, nil, err
+			}
+			post.Moderation = verdict
+		}
+
+		if post.Moderation == nil || post.Moderation.Action != filter.ACTION_HOLD {
+			publish = append(publish, post)
+			continue
+		}
+
+		us, err := u.userRep.FindByName(post.Author)
+		if err != nil {
+			return nil, nil, errors.Wrap(errors.New(forum.NOT_FOUND_ERR), "userRep.FindByName()")
+		}
+		post.Author = us.Nickname
+		held[post] = true
+	}
+	return publish, held, nil
+}
+
+func (u *ForumUcase) enqueue(item *models.ModerationItem, verdict *models.FilterVerdict) error {
+	item.Action = verdict.Action
+	item.Filter = verdict.Filter
+	item.Reason = verdict.Reason
+	if err := u.filterRep.Enqueue(item); err != nil {
+		return errors.Wrap(err, "filterRep.Enqueue()")
+	}
+	verdict.ID = item.ID
+	return nil
+}
+
+// filterSettings falls back to the configured defaults for forums without
+// settings of their own.
+func (u *ForumUcase) filterSettings(forumID int64) (*models.FilterSettings, error) {
+	s, err := u.filterRep.FindSettings(forumID)
+	if err == sql.ErrNoRows {
+		defaults := u.filters
+		return &defaults, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "filterRep.FindSettings()")
+	}
+	return s, nil
+}
+
+func (u *ForumUcase) GetFilters(slug string, moderator string) (*models.FilterSettings, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	f, err := u.repository.FindBySlug(slug)
+	if err != nil {
+		return nil, errors.Wrap(errors.New(forum.NOT_FOUND), "forumRep.FindBySlug()")
+	}
+	return u.filterSettings(f.ID)
+}
+
+// SetFilters replaces the filter settings of the forum.
+func (u *ForumUcase) SetFilters(slug string, moderator string, s *models.FilterSettings) (*models.FilterSettings, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	f, err := u.repository.FindBySlug(slug)
+	if err != nil {
+		return nil, errors.Wrap(errors.New(forum.NOT_FOUND), "forumRep.FindBySlug()")
+	}
+
+	if err := filter.Validate(s); err != nil {
+		return nil, errors.New(forum.WRONG_FILTERS + err.Error())
+	}
+
+	if err := u.filterRep.SaveSettings(f.ID, s); err != nil {
+		return nil, errors.Wrap(err, "filterRep.SaveSettings()")
+	}
+	return s, nil
+}
+
+func (u *ForumUcase) GetModerationQueue(slug string, moderator string, params *models.ListParameters) ([]*models.ModerationItem, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	f, err := u.repository.FindBySlug(slug)
+	if err != nil {
+		return nil, errors.Wrap(errors.New(forum.NOT_FOUND), "forumRep.FindBySlug()")
+	}
+
+	list, err := u.filterRep.Queue(f.Slug, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "filterRep.Queue()")
+	}
+	return list, nil
+}
+
+// Moderate resolves a queued item. Approving a held item publishes it
+// without filtering it again; flagged items are already published, so for
+// them the decision is only recorded.
+func (u *ForumUcase) Moderate(id int64, moderator string, approve bool) (*models.ModerationItem, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	item, err := u.filterRep.FindItem(id)
+	if err != nil {
+		return nil, errors.New(forum.ITEM_NOT_FOUND)
+	}
+
+	status := filter.STATUS_REJECTED
+	if approve {
+		status = filter.STATUS_APPROVED
+	}
+
+	resolved, err := u.filterRep.Resolve(id, status, moderator)
+	if err != nil {
+		return nil, errors.Wrap(err, "filterRep.Resolve()")
+	}
+	if !resolved {
+		return nil, errors.New(forum.ITEM_RESOLVED)
+	}
+	item.Status, item.Moderator = status, moderator
+
+	if !approve || item.Action != filter.ACTION_HOLD {
+		return item, nil
+	}
+
+	if item.Published, err = u.publishHeld(item); err != nil {
+		if err := u.filterRep.Reopen(id); err != nil {
+			return nil, errors.Wrap(err, "filterRep.Reopen()")
+		}
+		return nil, err
+	}
+
+	if err := u.filterRep.SetPublished(id, item.Published); err != nil {
+		return nil, errors.Wrap(err, "filterRep.SetPublished()")
+	}
+	return item, nil
+}
+
+func (u *ForumUcase) publishHeld(item *models.ModerationItem) (int64, error) {
+	if item.Kind == filter.KIND_THREAD {
+		t := &models.Thread{
+			Forum:		item.Forum,
+			Author:		item.Author,
+			Title:		item.Title,
+			Slug:		item.Slug,
+			Message:	item.Message,
+			Tags:		item.Tags,
+		}
+		if _, err := u.createThread(t, false); err != nil {
+			return 0, err
+		}
+		return t.ID, nil
+	}
+
+	p := &models.Post{
+		Author:		item.Author,
+		Parent:		item.Parent,
+		Message:	item.Message,
+	}
+	for _, quoted := range item.Quotes {
+		p.Quotes = append(p.Quotes, &models.Quote{Post: quoted})
+	}
+	if err := u.createPosts(strconv.FormatInt(item.Thread, 10), []*models.Post{p}, false); err != nil {
+		return 0, err
+	}
+	return p.ID, nil
+}
@@ -1,25 +1,59 @@
 package forum_ucase
 
 import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/filter"
 	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/markdown"
+	"github.com/efimovad/Forums.git/internal/app/notification"
+	"github.com/efimovad/Forums.git/internal/app/poll"
+	"github.com/efimovad/Forums.git/internal/app/reaction"
+	"github.com/efimovad/Forums.git/internal/app/subscription"
 	"github.com/efimovad/Forums.git/internal/app/user"
 	"github.com/efimovad/Forums.git/internal/models"
 	"github.com/pkg/errors"
+	"log"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type ForumUcase struct {
 	repository	forum.Repository
 	userRep		user.Repository
+	reactionRep	reaction.Repository
+	notifier	notification.Usecase
+	subscriptionRep	subscription.Repository
+	pollRep		poll.Repository
+	renderer	*markdown.Renderer
+	filterRep	filter.Repository
+	pipeline	*filter.Pipeline
+	filters		models.FilterSettings
+	moderators	map[string]bool
 	mux			sync.Mutex
 }
 
-func NewForumUsecase(r forum.Repository, ur user.Repository) forum.Usecase {
+func NewForumUsecase(r forum.Repository, ur user.Repository, rr reaction.Repository,
+	n notification.Usecase, sr subscription.Repository, pr poll.Repository, mr *markdown.Renderer,
+	fr filter.Repository, fp *filter.Pipeline, filters models.FilterSettings, moderators []string) forum.Usecase {
+	mods := make(map[string]bool, len(moderators))
+	for _, nickname := range moderators {
+		mods[strings.ToLower(nickname)] = true
+	}
+
 	return &ForumUcase{
-		repository: r,
-		userRep:	ur,
+		repository:		r,
+		userRep:		ur,
+		reactionRep:	rr,
+		notifier:		n,
+		subscriptionRep:	sr,
+		pollRep:		pr,
+		renderer:		mr,
+		filterRep:		fr,
+		pipeline:		fp,
+		filters:		filters,
+		moderators:		mods,
 	}
 }
 
@@ -36,10 +70,24 @@ func (u *ForumUcase) CreateForum(newForum *models.Forum) (*models.Forum, error)
 
 	newForum.User = us.Nickname
 
+	if newForum.Parent != "" {
+		parent, err := u.repository.FindBySlug(newForum.Parent)
+		if err != nil {
+			return nil, errors.New(forum.PARENT_NOT_FOUND + newForum.Parent)
+		}
+		newForum.Parent, newForum.ParentID = parent.Slug, parent.ID
+	}
+
 	return nil, u.repository.CreateForum(newForum)
 }
 
 func (u *ForumUcase) CreateThread(newThread *models.Thread) (*models.Thread, error) {
+	return u.createThread(newThread, true)
+}
+
+// createThread creates the thread unless the content filters hold it back.
+// Approved threads come here again with screen turned off.
+func (u *ForumUcase) createThread(newThread *models.Thread, screen bool) (*models.Thread, error) {
 	if newThread.Slug != "" {
 		t, err := u.repository.FindThreadBySlug(newThread.Slug)
 		if newThread.Slug != "" && err == nil {
@@ -61,7 +109,48 @@ func (u *ForumUcase) CreateThread(newThread *models.Thread) (*models.Thread, err
 
 	newThread.Author = us.Nickname
 
-	return nil, u.repository.CreateThread(newThread)
+	if newThread.Tags, err = normalizeTags(newThread.Tags); err != nil {
+		return nil, err
+	}
+
+	newThread.Moderation = nil
+	if screen {
+		if newThread.Moderation, err = u.screen(f, newThread.Author, newThread.Title, newThread.Message); err != nil {
+			return nil, err
+		}
+	}
+
+	item := &models.ModerationItem{
+		Kind:		filter.KIND_THREAD,
+		Forum:		newThread.Forum,
+		Author:		newThread.Author,
+		Title:		newThread.Title,
+		Slug:		newThread.Slug,
+		Message:	newThread.Message,
+		Tags:		newThread.Tags,
+	}
+
+	if newThread.Moderation != nil && newThread.Moderation.Action == filter.ACTION_HOLD {
+		return nil, u.enqueue(item, newThread.Moderation)
+	}
+
+	if err := u.repository.CreateThread(newThread); err != nil {
+		return nil, err
+	}
+
+	if newThread.Moderation != nil {
+		item.Published = newThread.ID
+		if err := u.enqueue(item, newThread.Moderation); err != nil {
+			log.Println(err)
+		}
+	}
+
+	if err := u.notifier.ThreadCreated(newThread); err != nil {
+		log.Println(errors.Wrap(err, "notifier.ThreadCreated()"))
+	}
+	u.subscribe(newThread.ID, newThread.Author)
+
+	return nil, nil
 }
 
 func (u *ForumUcase) GetForum(slug string) (*models.Forum, error) {
@@ -72,20 +161,246 @@ func (u *ForumUcase) GetForum(slug string) (*models.Forum, error) {
 	return f, nil
 }
 
+// GetForumTree returns top level forums with their sub-forums nested in
+// children. total_posts and total_threads sum up the whole subtree.
+func (u *ForumUcase) GetForumTree() ([]*models.Forum, error) {
+	forums, err := u.repository.ListForums()
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.ListForums()")
+	}
+
+	byID := make(map[int64]*models.Forum, len(forums))
+	for _, f := range forums {
+		byID[f.ID] = f
+	}
+
+	roots := make([]*models.Forum, 0)
+	children := make(map[int64][]*models.Forum)
+	for _, f := range forums {
+		if _, ok := byID[f.ParentID]; !ok {
+			roots = append(roots, f)
+			continue
+		}
+		children[f.ParentID] = append(children[f.ParentID], f)
+	}
+
+	placed := make(map[int64]bool, len(forums))
+	var place func(f *models.Forum)
+	place = func(f *models.Forum) {
+		placed[f.ID] = true
+		for _, child := range children[f.ID] {
+			if placed[child.ID] {
+				continue
+			}
+			child.Parent = f.Slug
+			f.Children = append(f.Children, child)
+			place(child)
+		}
+	}
+
+	for _, f := range roots {
+		place(f)
+	}
+	// Forums in a cycle have no root above them; they are shown from the
+	// top level rather than left out.
+	for _, f := range forums {
+		if !placed[f.ID] {
+			roots = append(roots, f)
+			place(f)
+		}
+	}
+
+	for _, f := range roots {
+		sumTotals(f)
+	}
+	return roots, nil
+}
+
+func sumTotals(f *models.Forum) {
+	f.TotalPosts, f.TotalThreads = f.Posts, f.Threads
+	for _, child := range f.Children {
+		sumTotals(child)
+		f.TotalPosts += child.TotalPosts
+		f.TotalThreads += child.TotalThreads
+	}
+}
+
+// subforums returns the lowercased slugs of all forums below the given one.
+func (u *ForumUcase) subforums(id int64) ([]string, error) {
+	forums, err := u.repository.ListForums()
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.ListForums()")
+	}
+
+	children := make(map[int64][]*models.Forum)
+	for _, f := range forums {
+		children[f.ParentID] = append(children[f.ParentID], f)
+	}
+
+	slugs := make([]string, 0)
+	seen := map[int64]bool{id: true}
+	queue := []int64{id}
+	for len(queue) > 0 {
+		for _, child := range children[queue[0]] {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			slugs = append(slugs, strings.ToLower(child.Slug))
+			queue = append(queue, child.ID)
+		}
+		queue = queue[1:]
+	}
+	return slugs, nil
+}
+
+// SetParent nests the forum into another one. An empty parent moves it back
+// to the top level.
+func (u *ForumUcase) SetParent(slug string, parent string, moderator string) (*models.Forum, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	f, err := u.repository.FindBySlug(slug)
+	if err != nil {
+		return nil, errors.New(forum.NOT_FOUND)
+	}
+
+	if parent == "" {
+		if err := u.repository.SetParent(f, nil); err != nil {
+			return nil, errors.Wrap(err, "repository.SetParent()")
+		}
+		return f, nil
+	}
+
+	p, err := u.repository.FindBySlug(parent)
+	if err != nil {
+		return nil, errors.New(forum.PARENT_NOT_FOUND + parent)
+	}
+
+	below, err := u.subforums(f.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.ID == f.ID {
+		return nil, errors.New(forum.FORUM_CYCLE)
+	}
+	for _, s := range below {
+		if s == strings.ToLower(p.Slug) {
+			return nil, errors.New(forum.FORUM_CYCLE)
+		}
+	}
+
+	if err := u.repository.SetParent(f, p); err != nil && err.Error() == forum.FORUM_CYCLE {
+		return nil, err
+	} else if err != nil {
+		return nil, errors.Wrap(err, "repository.SetParent()")
+	}
+	return f, nil
+}
+
+func (u *ForumUcase) GetTags(slug string) ([]*models.TagCount, error) {
+	f, err := u.repository.FindBySlug(slug)
+	if err != nil {
+		return nil, errors.New(forum.NOT_FOUND)
+	}
+	return u.repository.ForumTags(f.Slug)
+}
+
+// RenameForum changes the forum slug. The old slug keeps resolving to the
+// forum.
+func (u *ForumUcase) RenameForum(slug string, newSlug string, moderator string) (*models.Forum, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	if newSlug == "" {
+		return nil, errors.New(forum.WRONG_INPUT)
+	}
+
+	f, err := u.repository.FindBySlug(slug)
+	if err != nil {
+		return nil, errors.New(forum.NOT_FOUND)
+	}
+
+	if other, err := u.repository.FindBySlug(newSlug); err == nil && other.ID != f.ID {
+		return nil, errors.New(forum.FORUM_CONFLICT)
+	}
+
+	if newSlug == f.Slug {
+		return f, nil
+	}
+
+	if err := u.repository.RenameForum(f, newSlug); err != nil {
+		return nil, errors.Wrap(err, "repository.RenameForum()")
+	}
+	return f, nil
+}
+
 func (u *ForumUcase) GetThreads(slug string, params *models.ListParameters) ([]*models.Thread, error) {
-	_, err := u.repository.FindBySlug(slug)
+	f, err := u.repository.FindBySlug(slug)
 	if err != nil {
 		return nil, errors.Wrap(errors.New(forum.NOT_FOUND), "forumRep.FindBySlug()")
 	}
 
-	list, err := u.repository.GetThreads(slug, params)
+	if params.Subforums {
+		if params.Forums, err = u.subforums(f.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Filters never fail on too many tags, only stored tags are limited.
+	params.Tags, _ = normalizeTags(params.Tags)
+	if len(params.Tags) > forum.MAX_TAGS {
+		params.Tags = params.Tags[:forum.MAX_TAGS]
+	}
+
+	var reader *models.User
+	if params.User != "" {
+		if reader, err = u.repository.FindUser(params.User); err != nil {
+			return nil, errors.New(forum.USER_NOT_FOUND + params.User)
+		}
+	}
+
+	list, err := u.repository.GetThreads(f.Slug, params)
 	if err != nil {
 		return nil, err
 	}
+
+	if err := u.withThreadTags(list...); err != nil {
+		return nil, err
+	}
+
+	if err := u.withThreadReactions(list...); err != nil {
+		return nil, err
+	}
+
+	if reader != nil && len(list) != 0 {
+		ids := make([]int64, 0, len(list))
+		for _, t := range list {
+			ids = append(ids, t.ID)
+		}
+
+		counts, err := u.repository.UnreadCounts(reader.Nickname, ids)
+		if err != nil {
+			return nil, errors.Wrap(err, "repository.UnreadCounts()")
+		}
+		for _, t := range list {
+			n := counts[t.ID]
+			t.Unread = &n
+		}
+	}
 	return list, nil
 }
 
 func (u *ForumUcase) CreatePosts(currForum string, posts []*models.Post) error {
+	return u.createPosts(currForum, posts, true)
+}
+
+// createPosts publishes the posts the content filters let through and queues
+// the ones they hold back. A rejected post fails the whole batch.
+func (u *ForumUcase) createPosts(currForum string, posts []*models.Post, screen bool) error {
 	t, err := u.GetThread(currForum)
 	if err != nil {
 		return err
@@ -113,16 +428,73 @@ func (u *ForumUcase) CreatePosts(currForum string, posts []*models.Post) error {
 		}
 	}*/
 
+	if t.MovedTo != 0 {
+		return errors.New(forum.THREAD_MOVED)
+	}
+
 	if len(posts) == 0 {
 		return nil
 	}
 
-	err = u.repository.CreatePosts(posts, t)
+	if err := u.checkQuotes(posts); err != nil {
+		return err
+	}
+
+	publish, held, err := u.screenPosts(t, posts, screen)
 	if err != nil {
-		if strings.Contains(err.Error(), "posts_author_fkey") {
-			return errors.Wrap(errors.New(forum.NOT_FOUND_ERR), "userRep.FindByName()")
+		return err
+	}
+
+	if len(publish) != 0 {
+		err = u.repository.CreatePosts(publish, t)
+		if err != nil {
+			if strings.Contains(err.Error(), "posts_author_fkey") {
+				return errors.Wrap(errors.New(forum.NOT_FOUND_ERR), "userRep.FindByName()")
+			}
+			return errors.Wrap(err, "CreatePosts")
+		}
+	}
+
+	for _, post := range posts {
+		if post.Moderation == nil {
+			continue
+		}
+
+		item := &models.ModerationItem{
+			Kind:		filter.KIND_POST,
+			Forum:		t.Forum,
+			Thread:		t.ID,
+			Parent:		post.Parent,
+			Author:		post.Author,
+			Message:	post.Message,
+			Published:	post.ID,
+		}
+		for _, quote := range post.Quotes {
+			item.Quotes = append(item.Quotes, quote.Post)
+		}
+		if err := u.enqueue(item, post.Moderation); err != nil {
+			if held[post] {
+				return err
+			}
+			log.Println(err)
+		}
+	}
+
+	if len(publish) == 0 {
+		return nil
+	}
+
+	// Posts are already stored, a failed notification must not fail the request.
+	if err := u.notifier.PostsCreated(t, publish); err != nil {
+		log.Println(errors.Wrap(err, "notifier.PostsCreated()"))
+	}
+
+	authors := make(map[string]bool)
+	for _, post := range publish {
+		if !authors[strings.ToLower(post.Author)] {
+			authors[strings.ToLower(post.Author)] = true
+			u.subscribe(t.ID, post.Author)
 		}
-		return errors.Wrap(err, "CreatePosts")
 	}
 	return nil
 }
@@ -151,6 +523,61 @@ func (u *ForumUcase) CreateVote(vote *models.Vote) (*models.Thread, error) {
 	return thread, nil
 }
 
+func (u *ForumUcase) GetVote(currThread string, nickname string) (*models.Vote, error) {
+	thread, err := u.GetThread(currThread)
+	if err != nil {
+		return nil, err
+	}
+
+	vote, err := u.repository.FindVote(nickname, thread)
+	if err != nil {
+		return nil, errors.New(forum.VOTE_NOT_FOUND + nickname)
+	}
+
+	vote.Thread = currThread
+	return vote, nil
+}
+
+func (u *ForumUcase) DeleteVote(currThread string, nickname string) (*models.Thread, error) {
+	thread, err := u.GetThread(currThread)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.repository.DeleteVote(nickname, thread); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(forum.VOTE_NOT_FOUND + nickname)
+		}
+		return nil, errors.Wrap(err, "repository.DeleteVote()")
+	}
+
+	return u.GetThread(strconv.FormatInt(thread.ID, 10))
+}
+
+func (u *ForumUcase) CreatePostVote(vote *models.Vote) (*models.Post, error) {
+	if vote.Voice != 1 && vote.Voice != -1 {
+		return nil, errors.New(forum.WRONG_INPUT)
+	}
+
+	post, err := u.FindPost(vote.Post)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = u.repository.FindUser(vote.Nickname); err != nil {
+		return nil, errors.New(forum.USER_NOT_FOUND + vote.Nickname)
+	}
+
+	votesNum, err := u.repository.CreatePostVote(vote, post)
+	if err != nil {
+		return nil, err
+	}
+
+	post.Votes = votesNum
+
+	return post, nil
+}
+
 func (u *ForumUcase) GetThread(currThread string) (*models.Thread, error) {
 	var thread *models.Thread
 
@@ -170,6 +597,177 @@ func (u *ForumUcase) GetThread(currThread string) (*models.Thread, error) {
 	return thread, nil
 }
 
+func (u *ForumUcase) GetThreadDetails(currThread string) (*models.Thread, error) {
+	thread, err := u.GetThread(currThread)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.withThreadReactions(thread); err != nil {
+		return nil, err
+	}
+
+	if err := u.withThreadTags(thread); err != nil {
+		return nil, err
+	}
+
+	if err := u.withThreadPoll(thread); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// RenameThread changes the thread slug. The old slug keeps resolving to the
+// thread.
+func (u *ForumUcase) RenameThread(currThread string, newSlug string, moderator string) (*models.Thread, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	// A numeric slug could not be told apart from a thread id.
+	if _, err := strconv.ParseInt(newSlug, 10, 64); newSlug == "" || err == nil {
+		return nil, errors.New(forum.WRONG_INPUT)
+	}
+
+	thread, err := u.GetThread(currThread)
+	if err != nil {
+		return nil, err
+	}
+
+	if other, err := u.repository.FindThreadBySlug(newSlug); err == nil && other.ID != thread.ID {
+		return nil, errors.New(forum.THREAD_CONFLICT)
+	}
+
+	if newSlug != thread.Slug {
+		if err := u.repository.RenameThread(thread, newSlug); err != nil {
+			return nil, errors.Wrap(err, "repository.RenameThread()")
+		}
+	}
+	return u.GetThreadDetails(strconv.FormatInt(thread.ID, 10))
+}
+
+// isModerator tells whether the caller may moderate. Callers are identified
+// by their session, never by request data, so "" is an anonymous request.
+func (u *ForumUcase) isModerator(nickname string) bool {
+	return nickname != "" && u.moderators[strings.ToLower(nickname)]
+}
+
+// MoveThread reassigns a thread to another forum. The old forum keeps a
+// stub thread with moved_to set, so links to it can be redirected.
+func (u *ForumUcase) MoveThread(currThread string, forumSlug string, moderator string) (*models.Thread, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	thread, err := u.GetThread(currThread)
+	if err != nil {
+		return nil, err
+	}
+
+	if thread.MovedTo != 0 {
+		return nil, errors.New(forum.THREAD_MOVED)
+	}
+
+	to, err := u.repository.FindBySlug(forumSlug)
+	if err != nil {
+		return nil, errors.New(forum.NOT_FOUND)
+	}
+
+	if strings.ToLower(to.Slug) == strings.ToLower(thread.Forum) {
+		return u.GetThreadDetails(currThread)
+	}
+
+	if _, err := u.repository.MoveThread(thread, to, moderator); err != nil {
+		return nil, errors.Wrap(err, "repository.MoveThread()")
+	}
+	return u.GetThreadDetails(strconv.FormatInt(thread.ID, 10))
+}
+
+// MergeThreads moves all posts of currThread into another thread and leaves
+// a stub behind.
+func (u *ForumUcase) MergeThreads(currThread string, into string, moderator string) (*models.Thread, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	from, err := u.GetThread(currThread)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := u.GetThread(into)
+	if err != nil {
+		return nil, err
+	}
+
+	if from.ID == target.ID {
+		return nil, errors.New(forum.WRONG_INPUT)
+	}
+
+	if from.MovedTo != 0 || target.MovedTo != 0 {
+		return nil, errors.New(forum.THREAD_MOVED)
+	}
+
+	if err := u.repository.MergeThreads(from, target, moderator); err != nil {
+		return nil, errors.Wrap(err, "repository.MergeThreads()")
+	}
+	return u.GetThreadDetails(strconv.FormatInt(target.ID, 10))
+}
+
+// SplitThread takes the post and its replies out into a new thread of the
+// same forum. Author, creation time and message default to the post's.
+func (u *ForumUcase) SplitThread(post int64, thread *models.Thread, moderator string) (*models.Thread, error) {
+	if !u.isModerator(moderator) {
+		return nil, errors.New(forum.FORBIDDEN)
+	}
+
+	if thread.Title == "" {
+		return nil, errors.New(forum.WRONG_INPUT)
+	}
+
+	root, err := u.repository.FindPost(post)
+	if err != nil {
+		return nil, errors.New(forum.POST_NOT_FOUND)
+	}
+
+	from, err := u.repository.FindThread(root.Thread)
+	if err != nil {
+		return nil, errors.New(forum.THREAD_NOT_FOUND)
+	}
+
+	if thread.Slug != "" {
+		if _, err := u.repository.FindThreadBySlug(thread.Slug); err == nil {
+			return nil, errors.New(forum.THREAD_CONFLICT)
+		}
+	}
+
+	thread.Forum = from.Forum
+	thread.Author = root.Author
+	if thread.Created, err = time.Parse(time.RFC3339Nano, root.Created); err != nil {
+		thread.Created = time.Now()
+	}
+	if thread.Message == "" {
+		thread.Message = root.Message
+	}
+
+	if err := u.repository.SplitThread(root, from, thread, moderator); err != nil {
+		return nil, errors.Wrap(err, "repository.SplitThread()")
+	}
+	return u.GetThreadDetails(strconv.FormatInt(thread.ID, 10))
+}
+
+func (u *ForumUcase) GetThreadAudit(currThread string) ([]*models.ThreadAudit, error) {
+	thread, err := u.GetThread(currThread)
+	if err != nil {
+		return nil, err
+	}
+	return u.repository.ThreadAudit(thread.ID)
+}
+
+// UpdateThread writes only the fields the request sets. The thread may come
+// from the cache, so neither its fields nor its version are written back:
+// the version check is against the one the client sent, in the update
+// itself, and the result is read again once the update dropped the entry.
 func (u *ForumUcase) UpdateThread(currThread string, thread *models.Thread) (*models.Thread, error) {
 	var exThread *models.Thread
 	id, err := strconv.ParseInt(currThread, 10, 64)
@@ -183,23 +781,60 @@ func (u *ForumUcase) UpdateThread(currThread string, thread *models.Thread) (*mo
 		return nil, errors.New(forum.THREAD_NOT_FOUND)
 	}
 
-	if thread.Title == "" && thread.Message == "" {
-		return exThread, nil
+	if thread.Title == "" && thread.Message == "" && thread.Tags == nil {
+		return u.GetThreadDetails(strconv.FormatInt(exThread.ID, 10))
 	}
 
-	if thread.Message != "" {
-		exThread.Message = thread.Message
+	update := &models.Thread{
+		ID:			exThread.ID,
+		Slug:		exThread.Slug,
+		Title:		thread.Title,
+		Message:	thread.Message,
+		Version:	thread.Version,
 	}
-
-	if thread.Title != "" {
-		exThread.Title = thread.Title
+	if thread.Tags != nil {
+		if update.Tags, err = normalizeTags(thread.Tags); err != nil {
+			return nil, err
+		}
 	}
 
-	if err = u.repository.UpdateThread(exThread); err != nil {
+	if err = u.repository.UpdateThread(update); err != nil && err.Error() != forum.VERSION_CONFLICT {
 		return nil, errors.Wrap(err, "repository.UpdateThread")
 	}
 
-	return exThread, nil
+	current, findErr := u.GetThreadDetails(strconv.FormatInt(exThread.ID, 10))
+	if findErr != nil {
+		return nil, errors.New(forum.THREAD_NOT_FOUND)
+	}
+	return current, err
+}
+
+func (u *ForumUcase) MarkRead(currThread string, nickname string, post int64) (*models.ReadMarker, error) {
+	thread, err := u.GetThread(currThread)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := u.repository.FindUser(nickname)
+	if err != nil {
+		return nil, errors.New(forum.USER_NOT_FOUND + nickname)
+	}
+
+	if post != 0 {
+		p, err := u.repository.FindPost(post)
+		if err != nil {
+			return nil, errors.New(forum.POST_NOT_FOUND)
+		}
+		if p.Thread != thread.ID {
+			return nil, errors.New(forum.POST_THREAD_CONFLICT)
+		}
+	}
+
+	marker, err := u.repository.MarkRead(reader.Nickname, thread, post)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.MarkRead()")
+	}
+	return marker, nil
 }
 
 func (u *ForumUcase) GetPosts(currThread string, params *models.ListParameters) ([]*models.Post, error){
@@ -212,6 +847,14 @@ func (u *ForumUcase) GetPosts(currThread string, params *models.ListParameters)
 	if err != nil {
 		return nil, err
 	}
+
+	if err := u.withPostReactions(posts...); err != nil {
+		return nil, err
+	}
+
+	if err := u.withPostQuotes(posts...); err != nil {
+		return nil, err
+	}
 	return posts, nil
 }
 
@@ -220,15 +863,23 @@ func (u *ForumUcase) FindPost(id int64) (*models.Post, error) {
 	if err != nil {
 		return nil, errors.New(forum.POST_NOT_FOUND)
 	}
+
+	if err := u.withPostReactions(post); err != nil {
+		return nil, err
+	}
+
+	if err := u.withPostQuotes(post); err != nil {
+		return nil, err
+	}
 	return post, nil
 }
 
 func (u *ForumUcase) FindPostDetail(id int64, related string) (*models.Combine, error) {
 	res := new(models.Combine)
 
-	post, err := u.repository.FindPost(id)
+	post, err := u.FindPost(id)
 	if err != nil {
-		return nil, errors.New(forum.POST_NOT_FOUND)
+		return nil, err
 	}
 
 	res.Post = post
@@ -257,6 +908,31 @@ func (u *ForumUcase) FindPostDetail(id int64, related string) (*models.Combine,
 		res.Author = postAuthor
 	}
 
+	if strings.Contains(related, "quotes") {
+		res.Quotes = make([]*models.Post, 0, len(post.Quotes))
+		for _, quote := range post.Quotes {
+			quoted, err := u.FindPost(quote.Post)
+			if err != nil {
+				return nil, err
+			}
+			res.Quotes = append(res.Quotes, quoted)
+		}
+	}
+
+	if strings.Contains(related, "quoted_by") {
+		quoting, err := u.repository.FindQuotingPosts(post.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "repository.FindQuotingPosts()")
+		}
+		if err := u.withPostReactions(quoting...); err != nil {
+			return nil, err
+		}
+		if err := u.withPostQuotes(quoting...); err != nil {
+			return nil, err
+		}
+		res.QuotedBy = quoting
+	}
+
 	return res, nil
 }
 
@@ -266,17 +942,33 @@ func (u *ForumUcase) UpdatePost(post *models.Post) (*models.Post, error) {
 		return nil, err
 	}
 
+	if post.Version != 0 && post.Version != currPost.Version {
+		return currPost, errors.New(forum.VERSION_CONFLICT)
+	}
+
 	if post.Message == "" || post.Message == currPost.Message {
 		return currPost, nil
 	}
 
+	old := *currPost
 	currPost.Message = post.Message
 	currPost.IsEdited = true
 
 	if err = u.repository.UpdatePost(currPost); err != nil {
+		if err.Error() == forum.VERSION_CONFLICT {
+			current, findErr := u.FindPost(post.ID)
+			if findErr != nil {
+				return nil, findErr
+			}
+			return current, err
+		}
 		return nil, err
 	}
 
+	if err = u.notifier.PostUpdated(&old, currPost); err != nil {
+		log.Println(errors.Wrap(err, "notifier.PostUpdated()"))
+	}
+
 	return currPost, nil
 }
 
@@ -291,4 +983,157 @@ func (u *ForumUcase) GetUsers(slug string, params models.ListParameters) ([]*mod
 		return nil, errors.Wrap(err, "repository.GetUsers()")
 	}
 	return users, nil
-}
\ No newline at end of file
+}
+
+// withThreadTags fills in tags. Threads without tags get nil, so the
+// field is left out of responses.
+func (u *ForumUcase) withThreadTags(threads ...*models.Thread) error {
+	if len(threads) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(threads))
+	for _, t := range threads {
+		ids = append(ids, t.ID)
+	}
+
+	tags, err := u.repository.FindTags(ids)
+	if err != nil {
+		return errors.Wrap(err, "repository.FindTags()")
+	}
+
+	for _, t := range threads {
+		t.Tags = tags[t.ID]
+	}
+	return nil
+}
+
+// withThreadPoll attaches the poll with its current results, if the thread
+// has one.
+func (u *ForumUcase) withThreadPoll(t *models.Thread) error {
+	p, err := u.pollRep.FindByThread(t.ID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "pollRep.FindByThread()")
+	}
+
+	t.Poll = p
+	return nil
+}
+
+func (u *ForumUcase) withThreadReactions(threads ...*models.Thread) error {
+	if len(threads) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(threads))
+	for _, t := range threads {
+		ids = append(ids, t.ID)
+	}
+
+	counts, err := u.reactionRep.CountForThreads(ids)
+	if err != nil {
+		return errors.Wrap(err, "reactionRep.CountForThreads()")
+	}
+
+	for _, t := range threads {
+		t.Reactions = counts[t.ID]
+	}
+	return nil
+}
+
+func (u *ForumUcase) withPostReactions(posts ...*models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+
+	counts, err := u.reactionRep.CountForPosts(ids)
+	if err != nil {
+		return errors.Wrap(err, "reactionRep.CountForPosts()")
+	}
+
+	for _, p := range posts {
+		p.Reactions = counts[p.ID]
+	}
+	return nil
+}
+
+// checkQuotes makes sure every quoted post exists and fills in its author
+// and thread, so the created posts are returned with complete quotes.
+func (u *ForumUcase) checkQuotes(posts []*models.Post) error {
+	found := make(map[int64]*models.Post)
+	for _, post := range posts {
+		for _, quote := range post.Quotes {
+			quoted, ok := found[quote.Post]
+			if !ok {
+				var err error
+				quoted, err = u.repository.FindPost(quote.Post)
+				if err == sql.ErrNoRows {
+					return errors.New(forum.QUOTE_NOT_FOUND + strconv.FormatInt(quote.Post, 10))
+				} else if err != nil {
+					return errors.Wrap(err, "repository.FindPost()")
+				}
+				found[quote.Post] = quoted
+			}
+
+			quote.Author = quoted.Author
+			quote.Thread = quoted.Thread
+			quote.Link = quoteLink(quoted.ID)
+		}
+	}
+	return nil
+}
+
+func (u *ForumUcase) withPostQuotes(posts ...*models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+
+	quotes, err := u.repository.FindQuotes(ids)
+	if err != nil {
+		return errors.Wrap(err, "repository.FindQuotes()")
+	}
+
+	for _, p := range posts {
+		p.Quotes = quotes[p.ID]
+		for _, quote := range p.Quotes {
+			quote.Link = quoteLink(quote.Post)
+		}
+	}
+	return nil
+}
+
+func quoteLink(id int64) string {
+	return "/api/post/" + strconv.FormatInt(id, 10) + "/details"
+}
+
+// subscribe follows a thread on behalf of its author or a replier.
+func (u *ForumUcase) subscribe(thread int64, nickname string) {
+	sub := &models.Subscription{Nickname: nickname, Thread: thread}
+	if err := u.subscriptionRep.Subscribe(sub); err != nil {
+		log.Println(errors.Wrap(err, "subscriptionRep.Subscribe()"))
+	}
+}
+
+func (u *ForumUcase) RenderThreads(threads ...*models.Thread) {
+	for _, t := range threads {
+		u.renderer.Thread(t)
+	}
+}
+
+func (u *ForumUcase) RenderPosts(posts ...*models.Post) {
+	for _, p := range posts {
+		u.renderer.Post(p)
+	}
+}
@@ -0,0 +1,38 @@
+package forum_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/pkg/errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// normalizeTags case-folds tags, turns inner whitespace into dashes and
+// drops empty and repeated ones. nil stays nil, so callers can tell "no
+// tags given" from "remove all tags".
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		if utf8.RuneCountInString(tag) > forum.MAX_TAG_LENGTH {
+			return nil, errors.New(forum.WRONG_TAGS)
+		}
+
+		seen[tag] = true
+		res = append(res, tag)
+	}
+
+	if len(res) > forum.MAX_TAGS {
+		return nil, errors.New(forum.WRONG_TAGS)
+	}
+	return res, nil
+}
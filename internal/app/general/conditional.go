@@ -0,0 +1,113 @@
+package general
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const PRECONDITION_FAILED = "Resource was modified, precondition failed"
+
+// ETag returns a strong validator for the JSON representation of data.
+func ETag(data interface{}) string {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	sum := sha1.Sum(body)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// NotModified sets ETag and Last-Modified on the response and, when the
+// request's validators still match, answers 304 and returns true.
+func NotModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !matchETag(inm, etag, true) {
+			return false
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil || modified.Truncate(time.Second).After(since) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// VersionETag is the validator of a versioned resource like a thread or a
+// post. The version changes with every edit, modified also with votes and
+// reactions, so the tag follows any change of the representation.
+func VersionETag(version int64, modified time.Time) string {
+	return `"` + strconv.FormatInt(version, 10) + "-" + strconv.FormatInt(modified.UnixNano(), 36) + `"`
+}
+
+// ContentETag is the validator of a versioned resource served together with
+// other data, like a post with its related objects. It still leads with the
+// version for IfMatchVersion, but hashes the whole representation, so a
+// change of anything embedded changes the tag.
+func ContentETag(version int64, data interface{}) string {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	sum := sha1.Sum(body)
+	return `"` + strconv.FormatInt(version, 10) + "-" + hex.EncodeToString(sum[:]) + `"`
+}
+
+// IfMatchVersion returns the version the request's If-Match header was made
+// for. ok is false when there is no header or it matches any version; a tag
+// that isn't one of VersionETag or ContentETag can never match and fails with
+// PRECONDITION_FAILED.
+func IfMatchVersion(r *http.Request) (version int64, ok bool, err error) {
+	im := strings.TrimSpace(r.Header.Get("If-Match"))
+	if im == "" || im == "*" {
+		return 0, false, nil
+	}
+
+	tag := strings.Trim(im, `"`)
+	dash := strings.IndexByte(tag, '-')
+	if dash <= 0 || strings.ContainsAny(tag, `",`) {
+		return 0, false, errors.New(PRECONDITION_FAILED)
+	}
+
+	version, err = strconv.ParseInt(tag[:dash], 10, 64)
+	if err != nil {
+		return 0, false, errors.New(PRECONDITION_FAILED)
+	}
+	return version, true, nil
+}
+
+func matchETag(header string, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = tag[2:]
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package general
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"strings"
+)
+
+// Canonical answers with a permanent redirect to the current route when the
+// route variable name holds something other than the canonical slug, for
+// example a slug the resource had before a rename. It reports whether the
+// redirect was sent.
+func Canonical(w http.ResponseWriter, r *http.Request, name string, canonical string) bool {
+	vars := mux.Vars(r)
+	if canonical == "" || strings.EqualFold(vars[name], canonical) {
+		return false
+	}
+
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+
+	pairs := make([]string, 0, 2 * len(vars))
+	for k, v := range vars {
+		if k == name {
+			v = canonical
+		}
+		pairs = append(pairs, k, v)
+	}
+
+	u, err := route.URL(pairs...)
+	if err != nil {
+		return false
+	}
+
+	u.RawQuery = r.URL.RawQuery
+	http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+	return true
+}
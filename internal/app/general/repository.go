@@ -5,4 +5,6 @@ import "github.com/efimovad/Forums.git/internal/models"
 type Repository interface {
 	DropAll() error
 	GetStatus() (*models.ServiceInfo, error)
+	CheckCounters() ([]*models.CounterDrift, error)
+	RepairCounters() (int64, error)
 }
@@ -15,7 +15,7 @@ func NewGeneralRepository(db *sql.DB) general.Repository {
 }
 
 func (r *Repository) DropAll() error {
-	if _, err := r.db.Exec("TRUNCATE votes, users, posts, threads, forums RESTART IDENTITY CASCADE;"); err != nil {
+	if _, err := r.db.Exec("TRUNCATE votes, post_votes, post_quotes, reactions, notifications, subscriptions, thread_reads, bookmarks, thread_audit, slug_aliases, thread_tags, poll_votes, poll_options, polls, attachments, drafts, scheduled, forum_filters, moderation_queue, users, posts, threads, forums RESTART IDENTITY CASCADE;"); err != nil {
 		return err
 	}
 
@@ -46,4 +46,133 @@ func (r *Repository) GetStatus() (*models.ServiceInfo, error) {
 	}
 
 	return info, nil
-}
\ No newline at end of file
+}
+
+func (r *Repository) CheckCounters() ([]*models.CounterDrift, error) {
+	rows, err := r.db.Query(`
+		SELECT f.slug, f.threads, f.posts,
+			(SELECT COUNT(*) FROM threads t WHERE LOWER(t.forum) = LOWER(f.slug) AND t.moved_to IS NULL),
+			(SELECT COUNT(*) FROM posts p WHERE LOWER(p.forum) = LOWER(f.slug))
+		FROM forums f
+		ORDER BY f.slug`)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []*models.CounterDrift
+	for rows.Next() {
+		var slug string
+		var threads, posts, actualThreads, actualPosts int64
+		if err := rows.Scan(&slug, &threads, &posts, &actualThreads, &actualPosts); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+
+		if threads != actualThreads {
+			drift = append(drift, &models.CounterDrift{Forum: slug, Counter: "threads", Stored: threads, Actual: actualThreads})
+		}
+		if posts != actualPosts {
+			drift = append(drift, &models.CounterDrift{Forum: slug, Counter: "posts", Stored: posts, Actual: actualPosts})
+		}
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(`
+		SELECT t.id, t.forum, t.votes, t.upvotes, t.downvotes,
+			COALESCE(SUM(v.vote), 0),
+			COUNT(*) FILTER (WHERE v.vote > 0),
+			COUNT(*) FILTER (WHERE v.vote < 0)
+		FROM threads t
+		LEFT JOIN votes v ON v.thread = t.id
+		GROUP BY t.id
+		HAVING t.votes IS DISTINCT FROM COALESCE(SUM(v.vote), 0) OR
+			t.upvotes IS DISTINCT FROM COUNT(*) FILTER (WHERE v.vote > 0) OR
+			t.downvotes IS DISTINCT FROM COUNT(*) FILTER (WHERE v.vote < 0)
+		ORDER BY t.id`)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var id int64
+		var slug string
+		var stored, actual [3]int64
+		if err := rows.Scan(&id, &slug, &stored[0], &stored[1], &stored[2], &actual[0], &actual[1], &actual[2]); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+
+		for i, counter := range []string{"votes", "upvotes", "downvotes"} {
+			if stored[i] != actual[i] {
+				drift = append(drift, &models.CounterDrift{Forum: slug, Thread: id, Counter: counter, Stored: stored[i], Actual: actual[i]})
+			}
+		}
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return drift, nil
+}
+
+// RepairCounters recomputes the forum and thread vote counters from the
+// actual rows and returns the number of forums and threads that were fixed.
+func (r *Repository) RepairCounters() (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE forums f
+			SET threads = c.threads, posts = c.posts
+			FROM (
+				SELECT id,
+					(SELECT COUNT(*) FROM threads t WHERE LOWER(t.forum) = LOWER(slug) AND t.moved_to IS NULL) AS threads,
+					(SELECT COUNT(*) FROM posts p WHERE LOWER(p.forum) = LOWER(slug)) AS posts
+				FROM forums
+			) c
+			WHERE f.id = c.id AND (f.threads IS DISTINCT FROM c.threads OR f.posts IS DISTINCT FROM c.posts)`)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	forums, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	res, err = tx.Exec(`
+		UPDATE threads t
+			SET votes = c.votes, upvotes = c.upvotes, downvotes = c.downvotes
+			FROM (
+				SELECT t.id,
+					COALESCE(SUM(v.vote), 0) AS votes,
+					COUNT(*) FILTER (WHERE v.vote > 0) AS upvotes,
+					COUNT(*) FILTER (WHERE v.vote < 0) AS downvotes
+				FROM threads t
+				LEFT JOIN votes v ON v.thread = t.id
+				GROUP BY t.id
+			) c
+			WHERE t.id = c.id AND (t.votes IS DISTINCT FROM c.votes OR
+				t.upvotes IS DISTINCT FROM c.upvotes OR t.downvotes IS DISTINCT FROM c.downvotes)`)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	threads, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return forums + threads, nil
+}
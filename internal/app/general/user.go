@@ -0,0 +1,69 @@
+package general
+
+import (
+	"context"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"net/http"
+)
+
+const sessionNickname = "nickname"
+
+// CurrentUser returns the nickname of the authenticated user or "" when the
+// request carries no valid session.
+func CurrentUser(r *http.Request) string {
+	if u, ok := r.Context().Value(CtxKeyUser).(*models.User); ok && u != nil {
+		return u.Nickname
+	}
+	return ""
+}
+
+// SessionMiddleware puts the user of the session cookie into the request
+// context. Requests without a session, or with one of a removed user, pass
+// through anonymously.
+func SessionMiddleware(store sessions.Store, find func(nickname string) (*models.User, error)) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := store.Get(r, SessionName)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			nickname, ok := session.Values[sessionNickname].(string)
+			if !ok || nickname == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			u, err := find(nickname)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), CtxKeyUser, u)))
+		})
+	}
+}
+
+// Login starts a session for the user.
+func Login(w http.ResponseWriter, r *http.Request, store sessions.Store, u *models.User) error {
+	session, err := store.Get(r, SessionName)
+	if err != nil && session == nil {
+		return err
+	}
+	session.Values[sessionNickname] = u.Nickname
+	return session.Save(r, w)
+}
+
+// Logout ends the session of the request, if any.
+func Logout(w http.ResponseWriter, r *http.Request, store sessions.Store) error {
+	session, err := store.Get(r, SessionName)
+	if err != nil && session == nil {
+		return err
+	}
+	delete(session.Values, sessionNickname)
+	session.Options.MaxAge = -1
+	return session.Save(r, w)
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+)
+
+type SMTPMailer struct {
+	addr	string
+	from	string
+	auth	smtp.Auth
+}
+
+// NewSMTPMailer sends mail through addr (host:port). Authentication is only
+// used when user is not empty.
+func NewSMTPMailer(addr string, user string, password string, from string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &SMTPMailer{
+		addr: addr,
+		from: from,
+	}
+	if user != "" {
+		m.auth = smtp.PlainAuth("", user, password, host)
+	}
+	return m, nil
+}
+
+// Send encodes the recipient and subject before they go into the headers:
+// both carry user data and must not be able to add headers of their own.
+func (m *SMTPMailer) Send(msg *Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(msg.Subject)
+
+	var body strings.Builder
+	body.WriteString("From: " + m.from + "\r\n")
+	body.WriteString("To: " + to.String() + "\r\n")
+	body.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(strings.Replace(msg.Body, "\n", "\r\n", -1))
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to.Address}, []byte(body.String()))
+}
@@ -0,0 +1,188 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingRe	= regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ulistRe		= regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	olistRe		= regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	ruleRe		= regexp.MustCompile(`^\s*((-\s*){3,}|(\*\s*){3,}|(_\s*){3,})$`)
+	quoteRe		= regexp.MustCompile(`^\s*>\s?(.*)$`)
+	fenceRe		= regexp.MustCompile("^\\s*(```|~~~)")
+
+	codeSpanRe	= regexp.MustCompile("`([^`]+)`")
+	linkRe		= regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	autoLinkRe	= regexp.MustCompile(`https?://[^\s<>"'\x00]+[^\s<>"'.,;:!?)\x00]`)
+	strongRe	= regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	emRe		= regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	strikeRe	= regexp.MustCompile(`~~([^~]+)~~`)
+	placeholderRe	= regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// Render converts Markdown to HTML. Raw HTML in the source is always
+// escaped and links are limited to safe schemes, so the output can be
+// embedded into a page as is.
+func Render(src string) string {
+	src = strings.Replace(src, "\r\n", "\n", -1)
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"))
+	return b.String()
+}
+
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case fenceRe.MatchString(line):
+			fence := fenceRe.FindStringSubmatch(line)[1]
+			i++
+			var code []string
+			for ; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			i++
+			b.WriteString("<pre><code>")
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+
+		case headingRe.MatchString(line):
+			m := headingRe.FindStringSubmatch(line)
+			level := strconv.Itoa(len(m[1]))
+			b.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+			i++
+
+		case ruleRe.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+
+		case quoteRe.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && quoteRe.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quoteRe.FindStringSubmatch(lines[i])[1])
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case ulistRe.MatchString(line):
+			i = renderList(b, lines, i, ulistRe, "ul")
+
+		case olistRe.MatchString(line):
+			i = renderList(b, lines, i, olistRe, "ol")
+
+		default:
+			var para []string
+			for ; i < len(lines) && isParagraphLine(lines[i]); i++ {
+				para = append(para, renderInline(strings.TrimSpace(lines[i])))
+			}
+			b.WriteString("<p>" + strings.Join(para, "<br>\n") + "</p>\n")
+		}
+	}
+}
+
+func renderList(b *strings.Builder, lines []string, i int, re *regexp.Regexp, tag string) int {
+	b.WriteString("<" + tag + ">\n")
+	for ; i < len(lines) && re.MatchString(lines[i]); i++ {
+		b.WriteString("<li>" + renderInline(re.FindStringSubmatch(lines[i])[1]) + "</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+func isParagraphLine(line string) bool {
+	return strings.TrimSpace(line) != "" &&
+		!fenceRe.MatchString(line) &&
+		!headingRe.MatchString(line) &&
+		!ruleRe.MatchString(line) &&
+		!quoteRe.MatchString(line) &&
+		!ulistRe.MatchString(line) &&
+		!olistRe.MatchString(line)
+}
+
+// renderInline handles code spans, links and emphasis. Code spans and links
+// are swapped for placeholders first so that emphasis markers inside them
+// (underscores in urls, for example) are left alone.
+func renderInline(text string) string {
+	var stash []string
+	hold := func(s string) string {
+		stash = append(stash, s)
+		return "\x00" + strconv.Itoa(len(stash) - 1) + "\x00"
+	}
+	// A held piece may hold earlier ones itself, like a code span inside a
+	// link label.
+	var expand func(s string) string
+	expand = func(s string) string {
+		return placeholderRe.ReplaceAllStringFunc(s, func(p string) string {
+			n, _ := strconv.Atoi(placeholderRe.FindStringSubmatch(p)[1])
+			return expand(stash[n])
+		})
+	}
+
+	text = strings.Replace(text, "\x00", "", -1)
+	text = codeSpanRe.ReplaceAllStringFunc(text, func(s string) string {
+		return hold("<code>" + html.EscapeString(codeSpanRe.FindStringSubmatch(s)[1]) + "</code>")
+	})
+	text = linkRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkRe.FindStringSubmatch(s)
+		label := emphasize(html.EscapeString(m[1]))
+		if !SafeURL(m[2]) {
+			return hold(label)
+		}
+		return hold(link(m[2], label))
+	})
+	// Bare urls stop at placeholders, or a link held above would end up
+	// inside the href of this one.
+	text = autoLinkRe.ReplaceAllStringFunc(text, func(s string) string {
+		return hold(link(s, html.EscapeString(s)))
+	})
+
+	return expand(emphasize(html.EscapeString(text)))
+}
+
+// emphasize turns the emphasis markers of already escaped text into tags.
+func emphasize(text string) string {
+	text = strongRe.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emRe.ReplaceAllString(text, "<em>$1$2</em>")
+	return strikeRe.ReplaceAllString(text, "<del>$1</del>")
+}
+
+func link(url string, label string) string {
+	return `<a href="` + html.EscapeString(url) + `" rel="nofollow noopener noreferrer">` + label + `</a>`
+}
+
+// SafeURL reports whether url is relative or uses the http, https or
+// mailto scheme. The url is looked at the way a browser would: entities
+// decoded and control characters and whitespace dropped. Backslashes are
+// refused, browsers read them as slashes and "/\host" is not relative.
+func SafeURL(url string) bool {
+	url = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(url))
+	url = strings.ToLower(url)
+	if strings.Contains(url, "\\") {
+		return false
+	}
+
+	colon := strings.Index(url, ":")
+	if colon < 0 || strings.ContainsAny(url[:colon], "/?#") {
+		return !strings.HasPrefix(url, "//")
+	}
+
+	switch url[:colon] {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
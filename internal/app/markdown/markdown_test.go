@@ -0,0 +1,96 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+const rel = `" rel="nofollow noopener noreferrer">`
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url		string
+		safe	bool
+	}{
+		{"https://example.com", true},
+		{"HTTP://example.com", true},
+		{"mailto:user@example.com", true},
+		{"/thread/1", true},
+		{"thread/1#post-2", true},
+		{"?page=2", true},
+		{"javascript:alert(1)", false},
+		{"JaVaScRiPt:alert(1)", false},
+		{" javascript:alert(1)", false},
+		{"java\tscript:alert(1)", false},
+		{"java\nscript:alert(1)", false},
+		{"\x01javascript:alert(1)", false},
+		{"javascript&#58;alert(1)", false},
+		{"javascript&colon;alert(1)", false},
+		{"&#106;avascript:alert(1)", false},
+		{"&#x6A;avascript:alert(1)", false},
+		{"java&#x09;script:alert(1)", false},
+		{"data:text/html;base64,PHNjcmlwdD4=", false},
+		{"vbscript:msgbox(1)", false},
+		{"//evil.com", false},
+		{"/\\evil.com", false},
+		{"\\\\evil.com", false},
+		{"https:\\\\evil.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := SafeURL(tt.url); got != tt.safe {
+			t.Errorf("SafeURL(%q) = %v, want %v", tt.url, got, tt.safe)
+		}
+	}
+}
+
+func TestRenderInline(t *testing.T) {
+	tests := []struct {
+		name	string
+		src		string
+		want	string
+	}{
+		{"javascript link", "[x](javascript:void)", "<p>x</p>\n"},
+		{"entity scheme", "[x](javascript&#58;void)", "<p>x</p>\n"},
+		{"entity letter", "[x](&#106;avascript:void)", "<p>x</p>\n"},
+		{"entity whitespace", "[x](java&#x09;script:void)", "<p>x</p>\n"},
+		{"backslash host", "[x](/\\evil.com)", "<p>x</p>\n"},
+		{"relative link", "[x](/thread/1)", `<p><a href="/thread/1` + rel + `x</a></p>` + "\n"},
+		{"underscores in url", "[x](https://example.com/a_b_c)",
+			`<p><a href="https://example.com/a_b_c` + rel + `x</a></p>` + "\n"},
+		{"raw html", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"link in code", "`[x](javascript:void)`", "<p><code>[x](javascript:void)</code></p>\n"},
+		{"code in link", "[`code`](https://example.com)",
+			`<p><a href="https://example.com` + rel + `<code>code</code></a></p>` + "\n"},
+		{"emphasis in link", "[**b**](https://example.com)",
+			`<p><a href="https://example.com` + rel + `<strong>b</strong></a></p>` + "\n"},
+		{"link in emphasis", "**a [b](https://example.com) c**",
+			`<p><strong>a <a href="https://example.com` + rel + `b</a> c</strong></p>` + "\n"},
+		{"em in strong", "**a *b* c**", "<p><strong>a <em>b</em> c</strong></p>\n"},
+		{"strong in em", "*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>\n"},
+		{"autolink", "see https://example.com/a_b.",
+			`<p>see <a href="https://example.com/a_b` + rel + `https://example.com/a_b</a>.</p>` + "\n"},
+		{"autolink before link", "http://a.b[x](/x/onmouseover=location=name//)",
+			`<p><a href="http://a.b` + rel + `http://a.b</a><a href="/x/onmouseover=location=name//` + rel + `x</a></p>` + "\n"},
+		{"autolink before code", "http://a.b`c`",
+			`<p><a href="http://a.b` + rel + `http://a.b</a><code>c</code></p>` + "\n"},
+	}
+
+	for _, tt := range tests {
+		if got := Render(tt.src); got != tt.want {
+			t.Errorf("%s: Render(%q) = %q, want %q", tt.name, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestRenderNoPlaceholders(t *testing.T) {
+	for _, src := range []string{
+		"[`a` and `b`](https://example.com)",
+		"[`x`](javascript:void)",
+		"\x000\x00 `a`",
+	} {
+		if got := Render(src); strings.Contains(got, "\x00") {
+			t.Errorf("Render(%q) = %q, left a placeholder", src, got)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package markdown
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/cache"
+	"github.com/efimovad/Forums.git/internal/models"
+	"strconv"
+)
+
+// Renderer fills the message_html fields of posts and threads. Rendered
+// output is cached under the id and version of the message, so edits never
+// serve stale html.
+type Renderer struct {
+	cache *cache.LRU
+}
+
+func NewRenderer(c *cache.LRU) *Renderer {
+	return &Renderer{cache: c}
+}
+
+func (r *Renderer) Post(p *models.Post) {
+	p.MessageHTML = r.render("post:" + strconv.FormatInt(p.ID, 10), p.Version, p.Message)
+}
+
+func (r *Renderer) Thread(t *models.Thread) {
+	t.MessageHTML = r.render("thread:" + strconv.FormatInt(t.ID, 10), t.Version, t.Message)
+}
+
+func (r *Renderer) render(key string, version int64, message string) string {
+	if r.cache == nil || version == 0 {
+		return Render(message)
+	}
+
+	key += ":" + strconv.FormatInt(version, 10)
+	if res, ok := r.cache.Get(key); ok {
+		return res.(string)
+	}
+
+	res := Render(message)
+	r.cache.Set(key, res)
+	return res
+}
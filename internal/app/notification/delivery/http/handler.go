@@ -0,0 +1,137 @@
+package notification_handler
+
+import (
+	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/efimovad/Forums.git/internal/app/notification"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Handler struct {
+	usecase			notification.Usecase
+	sessionStore	sessions.Store
+}
+
+func NewNotificationHandler(m *mux.Router, u notification.Usecase, sessionStore sessions.Store) {
+	handler := &Handler{
+		usecase:		u,
+		sessionStore:   sessionStore,
+	}
+
+	m.HandleFunc("/api/user/{nickname}/notifications", handler.GetNotifications).Methods(http.MethodGet)
+	m.HandleFunc("/api/user/{nickname}/notifications/read", handler.MarkRead).Methods(http.MethodPost)
+}
+
+// GetNotifications lists the newest notifications first unless desc=false is given.
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	nickname := vars["nickname"]
+
+	params := new(models.ListParameters)
+	str := r.URL.Query().Get("limit")
+	if str != "" {
+		limit, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Limit = limit
+	}
+
+	params.Desc = true
+	str = r.URL.Query().Get("desc")
+	if str != "" {
+		desc, err := strconv.ParseBool(str)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Desc = desc
+	}
+
+	params.Since = r.URL.Query().Get("since")
+	if params.Since != "" {
+		if _, err := strconv.ParseInt(params.Since, 10, 64); err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	var unread bool
+	str = r.URL.Query().Get("unread")
+	if str != "" {
+		var err error
+		if unread, err = strconv.ParseBool(str); err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	list, err := h.usecase.List(nickname, general.CurrentUser(r), unread, params)
+	if err != nil && err.Error() == notification.UNAUTHORIZED {
+		general.Error(w, r, http.StatusUnauthorized, err)
+		return
+	} else if err != nil && err.Error() == notification.FORBIDDEN {
+		general.Error(w, r, http.StatusForbidden, err)
+		return
+	} else if err != nil && strings.Contains(err.Error(), notification.USER_NOT_FOUND) {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+// MarkRead marks the notifications listed in "ids" as read, or all of them
+// when the body is empty or has no ids.
+func (h *Handler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "NotificationHandler.MarkRead<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	vars := mux.Vars(r)
+	nickname := vars["nickname"]
+
+	var body struct {
+		IDs []int64 `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		err = errors.Wrapf(err, "NotificationHandler.MarkRead<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	n, err := h.usecase.MarkRead(nickname, general.CurrentUser(r), body.IDs)
+	if err != nil && err.Error() == notification.UNAUTHORIZED {
+		general.Error(w, r, http.StatusUnauthorized, err)
+		return
+	} else if err != nil && err.Error() == notification.FORBIDDEN {
+		general.Error(w, r, http.StatusForbidden, err)
+		return
+	} else if err != nil && strings.Contains(err.Error(), notification.USER_NOT_FOUND) {
+		general.Error(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		general.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	general.Respond(w, r, http.StatusOK, map[string]int64{"updated": n})
+}
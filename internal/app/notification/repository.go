@@ -0,0 +1,12 @@
+package notification
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+type Repository interface {
+	Create(list []*models.Notification) error
+	List(nickname string, unread bool, params *models.ListParameters) ([]*models.Notification, error)
+	MarkRead(nickname string, ids []int64) (int64, error)
+
+	ResolveNicknames(names []string) ([]string, error)
+	FindPostAuthors(ids []int64) (map[int64]string, error)
+}
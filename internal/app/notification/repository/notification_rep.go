@@ -0,0 +1,157 @@
+package notification_rep
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/notification"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/lib/pq"
+	"strconv"
+	"strings"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) notification.Repository {
+	return &Repository{db}
+}
+
+func (r *Repository) Create(list []*models.Notification) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO notifications (nickname, kind, actor, thread, post) " +
+		"VALUES ($1, $2, $3, $4, $5) RETURNING id, created")
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, n := range list {
+		if err := stmt.QueryRow(n.Nickname, n.Kind, n.Actor, n.Thread, n.Post).Scan(&n.ID, &n.Created); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) List(nickname string, unread bool, params *models.ListParameters) ([]*models.Notification, error) {
+	var since int64
+	if params.Since != "" {
+		var err error
+		if since, err = strconv.ParseInt(params.Since, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.Query(
+		`SELECT id, nickname, kind, actor, thread, post, created, is_read
+				FROM notifications
+				WHERE LOWER(nickname) = LOWER($1) AND (NOT $2 OR NOT is_read) AND
+					($3 = 0 OR (NOT $4 AND id > $3) OR ($4 AND id < $3))
+				ORDER BY
+					CASE WHEN $4 THEN id END DESC,
+					CASE WHEN NOT $4 THEN id END ASC
+				LIMIT CASE WHEN $5 > 0 THEN $5 END;`,
+		nickname, unread, since, params.Desc, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.Notification, 0)
+	for rows.Next() {
+		n := new(models.Notification)
+		if err := rows.Scan(&n.ID, &n.Nickname, &n.Kind, &n.Actor, &n.Thread, &n.Post, &n.Created, &n.Read); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, n)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// MarkRead marks the given notifications as read, or all of them when ids is empty.
+func (r *Repository) MarkRead(nickname string, ids []int64) (int64, error) {
+	res, err := r.db.Exec(
+		"UPDATE notifications SET is_read = TRUE " +
+			"WHERE LOWER(nickname) = LOWER($1) AND NOT is_read AND (COALESCE(cardinality($2::bigint[]), 0) = 0 OR id = ANY($2))",
+		nickname,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *Repository) ResolveNicknames(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	lower := make([]string, 0, len(names))
+	for _, name := range names {
+		lower = append(lower, strings.ToLower(name))
+	}
+
+	rows, err := r.db.Query("SELECT nickname FROM users WHERE LOWER(nickname) = ANY($1)", pq.Array(lower))
+	if err != nil {
+		return nil, err
+	}
+
+	var found []string
+	for rows.Next() {
+		var nickname string
+		if err := rows.Scan(&nickname); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		found = append(found, nickname)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+func (r *Repository) FindPostAuthors(ids []int64) (map[int64]string, error) {
+	authors := make(map[int64]string)
+	if len(ids) == 0 {
+		return authors, nil
+	}
+
+	rows, err := r.db.Query("SELECT id, author FROM posts WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var id int64
+		var author string
+		if err := rows.Scan(&id, &author); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		authors[id] = author
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
@@ -0,0 +1,24 @@
+package notification
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+const (
+	USER_NOT_FOUND = "Can't find user by nickname: "
+	UNAUTHORIZED = "Log in to read notifications"
+	FORBIDDEN = "Notifications can only be read by their owner"
+
+	KIND_MENTION = "mention"
+	KIND_REPLY = "reply"
+	KIND_THREAD_POST = "thread_post"
+	KIND_SUBSCRIBED_THREAD = "subscribed_thread"
+	KIND_SUBSCRIBED_FORUM = "subscribed_forum"
+)
+
+type Usecase interface {
+	ThreadCreated(thread *models.Thread) error
+	PostsCreated(thread *models.Thread, posts []*models.Post) error
+	PostUpdated(old *models.Post, post *models.Post) error
+
+	List(nickname string, caller string, unread bool, params *models.ListParameters) ([]*models.Notification, error)
+	MarkRead(nickname string, caller string, ids []int64) (int64, error)
+}
@@ -0,0 +1,25 @@
+package notification_ucase
+
+import (
+	"regexp"
+	"strings"
+)
+
+var mentionRe = regexp.MustCompile(`(?:^|[^\w.@])@([\w.]+)`)
+
+// parseMentions returns the lower-cased nicknames mentioned as @nickname,
+// without duplicates and in order of appearance.
+func parseMentions(message string) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, m := range mentionRe.FindAllStringSubmatch(message, -1) {
+		name := strings.ToLower(strings.TrimRight(m[1], "."))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
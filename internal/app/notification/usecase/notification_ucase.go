@@ -0,0 +1,201 @@
+package notification_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/notification"
+	"github.com/efimovad/Forums.git/internal/app/subscription"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+type NotificationUcase struct {
+	repository		notification.Repository
+	userRep			user.Repository
+	subscriptionRep	subscription.Repository
+}
+
+func NewNotificationUsecase(r notification.Repository, ur user.Repository, sr subscription.Repository) notification.Usecase {
+	return &NotificationUcase{
+		repository:			r,
+		userRep:			ur,
+		subscriptionRep:	sr,
+	}
+}
+
+// ThreadCreated notifies the subscribers of the thread's forum.
+func (u *NotificationUcase) ThreadCreated(thread *models.Thread) error {
+	subscribers, err := u.subscriptionRep.ForumSubscribers(thread.Forum)
+	if err != nil {
+		return errors.Wrap(err, "subscriptionRep.ForumSubscribers()")
+	}
+
+	var list []*models.Notification
+	for _, nickname := range subscribers {
+		if strings.EqualFold(nickname, thread.Author) {
+			continue
+		}
+		list = append(list, &models.Notification{
+			Nickname:	nickname,
+			Kind:		notification.KIND_SUBSCRIBED_FORUM,
+			Actor:		thread.Author,
+			Thread:		thread.ID,
+		})
+	}
+
+	return errors.Wrap(u.repository.Create(list), "repository.Create()")
+}
+
+// PostsCreated notifies mentioned users, authors of the parent posts, the
+// thread author and the thread subscribers. Every user gets at most one
+// notification per post, in that order of precedence.
+func (u *NotificationUcase) PostsCreated(thread *models.Thread, posts []*models.Post) error {
+	var names []string
+	var parents []int64
+	for _, post := range posts {
+		names = append(names, parseMentions(post.Message)...)
+		if post.Parent != 0 {
+			parents = append(parents, post.Parent)
+		}
+	}
+
+	mentioned, err := u.resolve(names)
+	if err != nil {
+		return err
+	}
+
+	parentAuthors, err := u.repository.FindPostAuthors(parents)
+	if err != nil {
+		return errors.Wrap(err, "repository.FindPostAuthors()")
+	}
+
+	subscribers, err := u.subscriptionRep.ThreadSubscribers(thread.ID)
+	if err != nil {
+		return errors.Wrap(err, "subscriptionRep.ThreadSubscribers()")
+	}
+
+	var list []*models.Notification
+	for _, post := range posts {
+		seen := map[string]bool{strings.ToLower(post.Author): true}
+		add := func(nickname string, kind string) {
+			if nickname == "" || seen[strings.ToLower(nickname)] {
+				return
+			}
+			seen[strings.ToLower(nickname)] = true
+			list = append(list, &models.Notification{
+				Nickname:	nickname,
+				Kind:		kind,
+				Actor:		post.Author,
+				Thread:		thread.ID,
+				Post:		post.ID,
+			})
+		}
+
+		for _, name := range parseMentions(post.Message) {
+			add(mentioned[name], notification.KIND_MENTION)
+		}
+		add(parentAuthors[post.Parent], notification.KIND_REPLY)
+		add(thread.Author, notification.KIND_THREAD_POST)
+		for _, nickname := range subscribers {
+			add(nickname, notification.KIND_SUBSCRIBED_THREAD)
+		}
+	}
+
+	return errors.Wrap(u.repository.Create(list), "repository.Create()")
+}
+
+// PostUpdated notifies only users mentioned by the edit, not the ones that
+// were already mentioned before it.
+func (u *NotificationUcase) PostUpdated(old *models.Post, post *models.Post) error {
+	before := make(map[string]bool)
+	for _, name := range parseMentions(old.Message) {
+		before[name] = true
+	}
+
+	var names []string
+	for _, name := range parseMentions(post.Message) {
+		if !before[name] {
+			names = append(names, name)
+		}
+	}
+
+	mentioned, err := u.resolve(names)
+	if err != nil {
+		return err
+	}
+
+	var list []*models.Notification
+	for _, name := range names {
+		nickname := mentioned[name]
+		if nickname == "" || strings.EqualFold(nickname, post.Author) {
+			continue
+		}
+		list = append(list, &models.Notification{
+			Nickname:	nickname,
+			Kind:		notification.KIND_MENTION,
+			Actor:		post.Author,
+			Thread:		post.Thread,
+			Post:		post.ID,
+		})
+	}
+
+	return errors.Wrap(u.repository.Create(list), "repository.Create()")
+}
+
+// List and MarkRead work on the inbox of nickname, which only its owner,
+// the caller, may read or change.
+func (u *NotificationUcase) List(nickname string, caller string, unread bool, params *models.ListParameters) ([]*models.Notification, error) {
+	us, err := u.owner(nickname, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := u.repository.List(us.Nickname, unread, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.List()")
+	}
+	return list, nil
+}
+
+func (u *NotificationUcase) MarkRead(nickname string, caller string, ids []int64) (int64, error) {
+	us, err := u.owner(nickname, caller)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := u.repository.MarkRead(us.Nickname, ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "repository.MarkRead()")
+	}
+	return n, nil
+}
+
+func (u *NotificationUcase) owner(nickname string, caller string) (*models.User, error) {
+	if caller == "" {
+		return nil, errors.New(notification.UNAUTHORIZED)
+	}
+
+	us, err := u.userRep.FindByName(nickname)
+	if err != nil {
+		return nil, errors.New(notification.USER_NOT_FOUND + nickname)
+	}
+
+	if !strings.EqualFold(us.Nickname, caller) {
+		return nil, errors.New(notification.FORBIDDEN)
+	}
+	return us, nil
+}
+
+// resolve maps lower-cased nicknames to the ones stored in users.
+func (u *NotificationUcase) resolve(names []string) (map[string]string, error) {
+	found, err := u.repository.ResolveNicknames(names)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.ResolveNicknames()")
+	}
+
+	res := make(map[string]string, len(found))
+	for _, nickname := range found {
+		res[strings.ToLower(nickname)] = nickname
+	}
+	return res, nil
+}
@@ -0,0 +1,106 @@
+package poll_handler
+
+import (
+	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/efimovad/Forums.git/internal/app/poll"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+)
+
+type Handler struct {
+	usecase			poll.Usecase
+	sessionStore	sessions.Store
+}
+
+func NewPollHandler(m *mux.Router, u poll.Usecase, sessionStore sessions.Store) {
+	handler := &Handler{
+		usecase:		u,
+		sessionStore:   sessionStore,
+	}
+
+	m.HandleFunc("/api/thread/{slug_or_id}/poll", handler.GetPoll).Methods(http.MethodGet)
+	m.HandleFunc("/api/thread/{slug_or_id}/poll", handler.CreatePoll).Methods(http.MethodPost)
+	m.HandleFunc("/api/thread/{slug_or_id}/poll/vote", handler.Vote).Methods(http.MethodPost)
+}
+
+func (h *Handler) GetPoll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	p, err := h.usecase.Get(mux.Vars(r)["slug_or_id"])
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, p)
+}
+
+func (h *Handler) CreatePoll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "PollHandler.CreatePoll<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	p := new(models.Poll)
+	if err := json.NewDecoder(r.Body).Decode(p); err != nil {
+		err = errors.Wrapf(err, "PollHandler.CreatePoll<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.Create(mux.Vars(r)["slug_or_id"], general.CurrentUser(r), p)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusCreated, res)
+}
+
+func (h *Handler) Vote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "PollHandler.Vote<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	vote := new(models.PollVote)
+	if err := json.NewDecoder(r.Body).Decode(vote); err != nil {
+		err = errors.Wrapf(err, "PollHandler.Vote<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.Vote(mux.Vars(r)["slug_or_id"], general.CurrentUser(r), vote)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, err error) {
+	if strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+	} else if err.Error() == poll.UNAUTHORIZED {
+		general.Error(w, r, http.StatusUnauthorized, err)
+	} else if err.Error() == poll.FORBIDDEN || err.Error() == poll.VOTE_FORBIDDEN {
+		general.Error(w, r, http.StatusForbidden, err)
+	} else if err.Error() == poll.CONFLICT || err.Error() == poll.CLOSED {
+		general.Error(w, r, http.StatusConflict, err)
+	} else if err.Error() == poll.WRONG_INPUT {
+		general.Error(w, r, http.StatusBadRequest, err)
+	} else {
+		general.Error(w, r, http.StatusInternalServerError, err)
+	}
+}
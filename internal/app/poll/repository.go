@@ -0,0 +1,9 @@
+package poll
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+type Repository interface {
+	Create(p *models.Poll) error
+	FindByThread(thread int64) (*models.Poll, error)
+	Vote(p *models.Poll, nickname string, options []int64) error
+}
@@ -0,0 +1,168 @@
+package poll_rep
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/poll"
+	"github.com/efimovad/Forums.git/internal/models"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewPollRepository(db *sql.DB) poll.Repository {
+	return &Repository{db}
+}
+
+func (r *Repository) Create(p *models.Poll) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO polls (thread, question, multiple, anonymous, closes) VALUES ($1, $2, $3, $4, $5) " +
+			"RETURNING id, created",
+		p.Thread,
+		p.Question,
+		p.Multiple,
+		p.Anonymous,
+		p.Closes,
+	).Scan(&p.ID, &p.Created)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	for i, option := range p.Options {
+		err = tx.QueryRow(
+			"INSERT INTO poll_options (poll, position, text) VALUES ($1, $2, $3) RETURNING id",
+			p.ID,
+			i,
+			option.Text,
+		).Scan(&option.ID)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByThread returns the poll with current results.
+func (r *Repository) FindByThread(thread int64) (*models.Poll, error) {
+	p := new(models.Poll)
+	var closes sql.NullTime
+	err := r.db.QueryRow(
+		`SELECT id, thread, question, multiple, anonymous, closes, COALESCE(closes <= now(), false), created,
+				(SELECT COUNT(DISTINCT LOWER(nickname)) FROM poll_votes v WHERE v.poll = polls.id)
+				FROM polls WHERE thread = $1`,
+		thread,
+	).Scan(&p.ID, &p.Thread, &p.Question, &p.Multiple, &p.Anonymous, &closes, &p.Closed, &p.Created, &p.Voters)
+	if err != nil {
+		return nil, err
+	}
+
+	if closes.Valid {
+		p.Closes = &closes.Time
+	}
+
+	rows, err := r.db.Query(
+		`SELECT o.id, o.text, COUNT(v.id)
+				FROM poll_options o
+				LEFT JOIN poll_votes v ON v.option = o.id
+				WHERE o.poll = $1
+				GROUP BY o.id, o.text, o.position
+				ORDER BY o.position`,
+		p.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*models.PollOption)
+	for rows.Next() {
+		option := new(models.PollOption)
+		if err := rows.Scan(&option.ID, &option.Text, &option.Votes); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		p.Options = append(p.Options, option)
+		byID[option.ID] = option
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	if p.Anonymous {
+		return p, nil
+	}
+
+	rows, err = r.db.Query("SELECT option, nickname FROM poll_votes WHERE poll = $1 ORDER BY id", p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var option int64
+		var nickname string
+		if err := rows.Scan(&option, &nickname); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		if o, ok := byID[option]; ok {
+			o.Voters = append(o.Voters, nickname)
+		}
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Vote replaces the user's previous choice in the poll. The poll row is
+// locked first, so concurrent votes of one user can't both keep their
+// choice in a single choice poll.
+func (r *Repository) Vote(p *models.Poll, nickname string, options []int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("SELECT id FROM polls WHERE id = $1 FOR UPDATE", p.ID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec(
+		"DELETE FROM poll_votes WHERE poll = $1 AND LOWER(nickname) = LOWER($2)",
+		p.ID,
+		nickname,
+	); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	for _, option := range options {
+		if _, err = tx.Exec(
+			"INSERT INTO poll_votes (poll, option, nickname) VALUES ($1, $2, $3)",
+			p.ID,
+			option,
+			nickname,
+		); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	// The results are part of the thread, so it counts as modified.
+	if _, err = tx.Exec("UPDATE threads SET modified = now() WHERE id = $1", p.Thread); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,27 @@
+package poll
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+const (
+	NOT_FOUND = "Can't find poll of thread"
+	THREAD_NOT_FOUND = "Can't find such thread"
+	USER_NOT_FOUND = "Can't find user by nickname: "
+	OPTION_NOT_FOUND = "Can't find poll option: "
+	CONFLICT = "Thread already has a poll"
+	CLOSED = "Poll is closed"
+	FORBIDDEN = "Only the thread author can add a poll"
+	UNAUTHORIZED = "Log in to vote"
+	VOTE_FORBIDDEN = "Votes can only be cast by the logged in user"
+	WRONG_INPUT = "Wrong poll input"
+)
+
+const (
+	MIN_OPTIONS = 2
+	MAX_OPTIONS = 20
+)
+
+type Usecase interface {
+	Create(slugOrID string, nickname string, p *models.Poll) (*models.Poll, error)
+	Get(slugOrID string) (*models.Poll, error)
+	Vote(slugOrID string, nickname string, vote *models.PollVote) (*models.Poll, error)
+}
@@ -0,0 +1,129 @@
+package poll_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/poll"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PollUcase struct {
+	repository	poll.Repository
+	forumUcase	forum.Usecase
+	userRep		user.Repository
+}
+
+func NewPollUsecase(r poll.Repository, fu forum.Usecase, ur user.Repository) poll.Usecase {
+	return &PollUcase{
+		repository: r,
+		forumUcase: fu,
+		userRep:	ur,
+	}
+}
+
+// Create attaches a poll to the thread. Only the thread author may do it
+// and a thread has at most one poll.
+func (u *PollUcase) Create(slugOrID string, nickname string, p *models.Poll) (*models.Poll, error) {
+	thread, err := u.forumUcase.GetThread(slugOrID)
+	if err != nil {
+		return nil, errors.New(poll.THREAD_NOT_FOUND)
+	}
+
+	if !strings.EqualFold(nickname, thread.Author) {
+		return nil, errors.New(poll.FORBIDDEN)
+	}
+
+	p.Question = strings.TrimSpace(p.Question)
+	if p.Question == "" || len(p.Options) < poll.MIN_OPTIONS || len(p.Options) > poll.MAX_OPTIONS {
+		return nil, errors.New(poll.WRONG_INPUT)
+	}
+
+	for _, option := range p.Options {
+		option.Text = strings.TrimSpace(option.Text)
+		if option.Text == "" {
+			return nil, errors.New(poll.WRONG_INPUT)
+		}
+	}
+
+	if p.Closes != nil && !p.Closes.After(time.Now()) {
+		return nil, errors.New(poll.WRONG_INPUT)
+	}
+
+	if _, err := u.repository.FindByThread(thread.ID); err == nil {
+		return nil, errors.New(poll.CONFLICT)
+	}
+
+	p.Thread = thread.ID
+	if err := u.repository.Create(p); err != nil {
+		return nil, errors.Wrap(err, "repository.Create()")
+	}
+	return u.repository.FindByThread(thread.ID)
+}
+
+func (u *PollUcase) Get(slugOrID string) (*models.Poll, error) {
+	thread, err := u.forumUcase.GetThread(slugOrID)
+	if err != nil {
+		return nil, errors.New(poll.THREAD_NOT_FOUND)
+	}
+
+	p, err := u.repository.FindByThread(thread.ID)
+	if err != nil {
+		return nil, errors.New(poll.NOT_FOUND)
+	}
+	return p, nil
+}
+
+// Vote records the choice of the logged in user. Voting again replaces the
+// previous choice, so every user counts once per poll, like thread votes.
+func (u *PollUcase) Vote(slugOrID string, nickname string, vote *models.PollVote) (*models.Poll, error) {
+	if nickname == "" {
+		return nil, errors.New(poll.UNAUTHORIZED)
+	}
+	if vote.Nickname != "" && !strings.EqualFold(vote.Nickname, nickname) {
+		return nil, errors.New(poll.VOTE_FORBIDDEN)
+	}
+
+	p, err := u.Get(slugOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Closed {
+		return nil, errors.New(poll.CLOSED)
+	}
+
+	us, err := u.userRep.FindByName(nickname)
+	if err != nil {
+		return nil, errors.New(poll.USER_NOT_FOUND + nickname)
+	}
+
+	if len(vote.Options) == 0 || (!p.Multiple && len(vote.Options) > 1) {
+		return nil, errors.New(poll.WRONG_INPUT)
+	}
+
+	known := make(map[int64]bool, len(p.Options))
+	for _, option := range p.Options {
+		known[option.ID] = true
+	}
+
+	chosen := make([]int64, 0, len(vote.Options))
+	seen := make(map[int64]bool, len(vote.Options))
+	for _, id := range vote.Options {
+		if !known[id] {
+			return nil, errors.New(poll.OPTION_NOT_FOUND + strconv.FormatInt(id, 10))
+		}
+		if !seen[id] {
+			seen[id] = true
+			chosen = append(chosen, id)
+		}
+	}
+
+	if err := u.repository.Vote(p, us.Nickname, chosen); err != nil {
+		return nil, errors.Wrap(err, "repository.Vote()")
+	}
+	return u.repository.FindByThread(p.Thread)
+}
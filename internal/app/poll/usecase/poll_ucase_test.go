@@ -0,0 +1,120 @@
+package poll_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/poll"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeRepository struct {
+	poll.Repository
+	poll	*models.Poll
+	votes	map[string][]int64
+}
+
+func (r *fakeRepository) FindByThread(thread int64) (*models.Poll, error) {
+	if r.poll == nil || r.poll.Thread != thread {
+		return nil, errors.New("no poll")
+	}
+	p := *r.poll
+	return &p, nil
+}
+
+func (r *fakeRepository) Vote(p *models.Poll, nickname string, options []int64) error {
+	r.votes[nickname] = options
+	return nil
+}
+
+type fakeForum struct {
+	forum.Usecase
+}
+
+func (f fakeForum) GetThread(currThread string) (*models.Thread, error) {
+	return &models.Thread{ID: 1, Author: "Author"}, nil
+}
+
+type fakeUsers struct {
+	user.Repository
+}
+
+func (u fakeUsers) FindByName(nickname string) (*models.User, error) {
+	if !strings.EqualFold(nickname, "voter") {
+		return nil, errors.New("no user")
+	}
+	return &models.User{Nickname: "Voter"}, nil
+}
+
+func newPoll(multiple bool, closed bool) *fakeRepository {
+	return &fakeRepository{
+		poll: &models.Poll{
+			Thread:		1,
+			Multiple:	multiple,
+			Closed:		closed,
+			Options:	[]*models.PollOption{{ID: 10}, {ID: 11}, {ID: 12}},
+		},
+		votes: make(map[string][]int64),
+	}
+}
+
+func TestVote(t *testing.T) {
+	tests := []struct {
+		name		string
+		multiple	bool
+		closed		bool
+		caller		string
+		vote		*models.PollVote
+		err			string
+		recorded	[]int64
+	}{
+		{"single", false, false, "voter", &models.PollVote{Options: []int64{11}}, "", []int64{11}},
+		{"body repeats caller", false, false, "voter", &models.PollVote{Nickname: "VOTER", Options: []int64{11}}, "", []int64{11}},
+		{"several on single choice", false, false, "voter", &models.PollVote{Options: []int64{10, 11}}, poll.WRONG_INPUT, nil},
+		{"several on multiple choice", true, false, "voter", &models.PollVote{Options: []int64{12, 10}}, "", []int64{12, 10}},
+		{"repeated option counts once", true, false, "voter", &models.PollVote{Options: []int64{10, 10, 11}}, "", []int64{10, 11}},
+		{"same option twice on single choice", false, false, "voter", &models.PollVote{Options: []int64{10, 10}}, poll.WRONG_INPUT, nil},
+		{"no option", false, false, "voter", &models.PollVote{}, poll.WRONG_INPUT, nil},
+		{"unknown option", false, false, "voter", &models.PollVote{Options: []int64{99}}, poll.OPTION_NOT_FOUND + "99", nil},
+		{"closed", false, true, "voter", &models.PollVote{Options: []int64{10}}, poll.CLOSED, nil},
+		{"anonymous", false, false, "", &models.PollVote{Options: []int64{10}}, poll.UNAUTHORIZED, nil},
+		{"for someone else", false, false, "voter", &models.PollVote{Nickname: "other", Options: []int64{10}}, poll.VOTE_FORBIDDEN, nil},
+	}
+
+	for _, tt := range tests {
+		rep := newPoll(tt.multiple, tt.closed)
+		u := NewPollUsecase(rep, fakeForum{}, fakeUsers{})
+
+		_, err := u.Vote("1", tt.caller, tt.vote)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: got error %v, want %q", tt.name, err, tt.err)
+			}
+			if len(rep.votes) != 0 {
+				t.Errorf("%s: vote was recorded", tt.name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := rep.votes["Voter"]; !reflect.DeepEqual(got, tt.recorded) {
+			t.Errorf("%s: recorded %v, want %v", tt.name, got, tt.recorded)
+		}
+	}
+}
+
+func TestCreateOnlyByThreadAuthor(t *testing.T) {
+	rep := &fakeRepository{votes: make(map[string][]int64)}
+	u := NewPollUsecase(rep, fakeForum{}, fakeUsers{})
+
+	p := &models.Poll{Question: "q", Options: []*models.PollOption{{Text: "a"}, {Text: "b"}}}
+	if _, err := u.Create("1", "voter", p); err == nil || err.Error() != poll.FORBIDDEN {
+		t.Errorf("got %v, want %q", err, poll.FORBIDDEN)
+	}
+}
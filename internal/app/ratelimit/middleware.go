@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TOO_MANY_REQUESTS = "Too many requests, retry later"
+
+type Config struct {
+	Read   Limit
+	Thread Limit
+	Post   Limit
+	Vote   Limit
+	// TrustedProxies lists the addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-For header is believed.
+	TrustedProxies []string
+}
+
+// Middleware limits requests per client. Clients are identified by the
+// authenticated user put into the request context, or by their IP address.
+func Middleware(store Store, config Config) mux.MiddlewareFunc {
+	proxies := parseProxies(config.TrustedProxies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class, limit := classify(r, config)
+			if limit.Rate <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ok, wait := store.Take(class+":"+clientKey(r, proxies), limit, time.Now())
+			if !ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				general.Error(w, r, http.StatusTooManyRequests, errors.New(TOO_MANY_REQUESTS))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func classify(r *http.Request, config Config) (string, Limit) {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return "read", config.Read
+	}
+
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", Limit{}
+	}
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return "", Limit{}
+	}
+
+	switch tpl {
+	case "/api/forum/{slug}/create", "/api/forum/{slug}/schedule":
+		return "thread", config.Thread
+	case "/api/thread/{slug_or_id}/create", "/api/thread/{slug_or_id}/schedule":
+		return "post", config.Post
+	case "/api/thread/{slug_or_id}/vote", "/api/post/{id}/vote",
+		"/api/thread/{slug_or_id}/reactions", "/api/post/{id}/reactions", "/api/thread/{slug_or_id}/poll/vote":
+		return "vote", config.Vote
+	}
+	return "", Limit{}
+}
+
+func clientKey(r *http.Request, proxies []*net.IPNet) string {
+	if u, ok := r.Context().Value(general.CtxKeyUser).(*models.User); ok && u != nil {
+		return "user:" + strings.ToLower(u.Nickname)
+	}
+	return "ip:" + clientIP(r, proxies)
+}
+
+// clientIP is the remote address unless the request came through a trusted
+// proxy. Then X-Forwarded-For is read from the right, since only the hops
+// appended by trusted proxies can't be forged, and the first untrusted hop
+// is the client.
+func clientIP(r *http.Request, proxies []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !trusted(host, proxies) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header["X-Forwarded-For"], ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !trusted(hop, proxies) {
+			return hop
+		}
+		host = hop
+	}
+	return host
+}
+
+func trusted(addr string, proxies []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range proxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseProxies(list []string) []*net.IPNet {
+	proxies := make([]*net.IPNet, 0, len(list))
+	for _, s := range list {
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil && ip.To4() != nil {
+				s += "/32"
+			} else {
+				s += "/128"
+			}
+		}
+
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			log.Println(errors.Wrap(err, "ratelimit: trusted proxy "+s))
+			continue
+		}
+		proxies = append(proxies, n)
+	}
+	return proxies
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	s := NewMemoryStore(time.Minute)
+	limit := Limit{Rate: 2, Burst: 3}
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := s.Take("a", limit, now); !ok {
+			t.Fatalf("request %d within the burst was refused", i+1)
+		}
+	}
+
+	ok, wait := s.Take("a", limit, now)
+	if ok {
+		t.Fatal("request past the burst was allowed")
+	}
+	if wait != 500 * time.Millisecond {
+		t.Errorf("got wait %v, want 500ms", wait)
+	}
+
+	if ok, _ := s.Take("b", limit, now); !ok {
+		t.Error("another key shares the bucket")
+	}
+
+	if ok, _ := s.Take("a", limit, now.Add(wait)); !ok {
+		t.Error("request after the wait was refused")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	s := NewMemoryStore(time.Minute)
+	now := time.Now()
+	s.Take("a", Limit{Rate: 1, Burst: 1}, now)
+	s.Take("b", Limit{Rate: 1, Burst: 1}, now.Add(2 * time.Minute))
+
+	if _, ok := s.buckets["a"]; ok {
+		t.Error("idle bucket wasn't swept")
+	}
+	if _, ok := s.buckets["b"]; !ok {
+		t.Error("active bucket was swept")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := parseProxies([]string{"10.0.0.1", "192.168.0.0/16"})
+	tests := []struct {
+		name	string
+		remote	string
+		xff		[]string
+		want	string
+	}{
+		{"direct", "1.2.3.4:5000", nil, "1.2.3.4"},
+		{"untrusted proxy", "1.2.3.4:5000", []string{"5.6.7.8"}, "1.2.3.4"},
+		{"trusted proxy", "10.0.0.1:5000", []string{"5.6.7.8"}, "5.6.7.8"},
+		{"forged hop", "10.0.0.1:5000", []string{"6.6.6.6, 5.6.7.8"}, "5.6.7.8"},
+		{"proxy chain", "10.0.0.1:5000", []string{"5.6.7.8", "192.168.1.1"}, "5.6.7.8"},
+		{"only proxies", "10.0.0.1:5000", []string{"192.168.1.1"}, "192.168.1.1"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remote
+		for _, v := range tt.xff {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		if got := clientIP(r, proxies); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit describes a token bucket: Rate tokens are added per second up to Burst.
+// A zero Rate disables limiting for the class.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Store keeps bucket state. MemoryStore is the in-process implementation;
+// a shared backend (e.g. redis) only has to satisfy the same interface.
+type Store interface {
+	// Take consumes one token from the bucket identified by key.
+	// When the bucket is empty it returns false and the time to wait.
+	Take(key string, limit Limit, now time.Time) (bool, time.Duration)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type MemoryStore struct {
+	buckets map[string]*bucket
+	mux     sync.Mutex
+	idle    time.Duration
+	swept   time.Time
+}
+
+func NewMemoryStore(idle time.Duration) *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		idle:    idle,
+	}
+}
+
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (bool, time.Duration) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.tokens) / limit.Rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// sweep drops buckets that have not been touched for the idle period,
+// so the map does not grow with every client ever seen.
+func (s *MemoryStore) sweep(now time.Time) {
+	if s.idle <= 0 || now.Sub(s.swept) < s.idle {
+		return
+	}
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > s.idle {
+			delete(s.buckets, key)
+		}
+	}
+	s.swept = now
+}
@@ -0,0 +1,188 @@
+package reaction_handler
+
+import (
+	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/efimovad/Forums.git/internal/app/reaction"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Handler struct {
+	usecase			reaction.Usecase
+	sessionStore	sessions.Store
+}
+
+func NewReactionHandler(m *mux.Router, u reaction.Usecase, sessionStore sessions.Store) {
+	handler := &Handler{
+		usecase:		u,
+		sessionStore:   sessionStore,
+	}
+
+	m.HandleFunc("/api/post/{id}/reactions", handler.GetPostReactions).Methods(http.MethodGet)
+	m.HandleFunc("/api/post/{id}/reactions", handler.AddPostReaction).Methods(http.MethodPost)
+	m.HandleFunc("/api/post/{id}/reactions", handler.RemovePostReaction).Methods(http.MethodDelete)
+
+	m.HandleFunc("/api/thread/{slug_or_id}/reactions", handler.GetThreadReactions).Methods(http.MethodGet)
+	m.HandleFunc("/api/thread/{slug_or_id}/reactions", handler.AddThreadReaction).Methods(http.MethodPost)
+	m.HandleFunc("/api/thread/{slug_or_id}/reactions", handler.RemoveThreadReaction).Methods(http.MethodDelete)
+}
+
+func (h *Handler) GetPostReactions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	list, err := h.usecase.GetPostReactions(id)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) AddPostReaction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	item, err := decodeReaction(r)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, errors.Wrap(err, "ReactionHandler.AddPostReaction<-Decode()"))
+		return
+	}
+	if !h.author(w, r, item) {
+		return
+	}
+
+	counts, err := h.usecase.AddToPost(id, item)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusCreated, counts)
+}
+
+func (h *Handler) RemovePostReaction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	item := queryReaction(r)
+	if !h.author(w, r, item) {
+		return
+	}
+
+	counts, err := h.usecase.RemoveFromPost(id, item)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, counts)
+}
+
+func (h *Handler) GetThreadReactions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	list, err := h.usecase.GetThreadReactions(mux.Vars(r)["slug_or_id"])
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) AddThreadReaction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	item, err := decodeReaction(r)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, errors.Wrap(err, "ReactionHandler.AddThreadReaction<-Decode()"))
+		return
+	}
+	if !h.author(w, r, item) {
+		return
+	}
+
+	counts, err := h.usecase.AddToThread(mux.Vars(r)["slug_or_id"], item)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusCreated, counts)
+}
+
+func (h *Handler) RemoveThreadReaction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	item := queryReaction(r)
+	if !h.author(w, r, item) {
+		return
+	}
+
+	counts, err := h.usecase.RemoveFromThread(mux.Vars(r)["slug_or_id"], item)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, counts)
+}
+
+// author makes the session user the author of the reaction. A nickname in
+// the request may only repeat it.
+func (h *Handler) author(w http.ResponseWriter, r *http.Request, item *models.Reaction) bool {
+	caller := general.CurrentUser(r)
+	if caller == "" {
+		general.Error(w, r, http.StatusUnauthorized, errors.New(reaction.UNAUTHORIZED))
+		return false
+	} else if item.Nickname != "" && !strings.EqualFold(item.Nickname, caller) {
+		general.Error(w, r, http.StatusForbidden, errors.New(reaction.FORBIDDEN))
+		return false
+	}
+	item.Nickname = caller
+	return true
+}
+
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, err error) {
+	if strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+	} else if strings.Contains(err.Error(), reaction.NOT_ALLOWED) {
+		general.Error(w, r, http.StatusBadRequest, err)
+	} else {
+		general.Error(w, r, http.StatusInternalServerError, err)
+	}
+}
+
+func decodeReaction(r *http.Request) (*models.Reaction, error) {
+	defer r.Body.Close()
+
+	item := new(models.Reaction)
+	if err := json.NewDecoder(r.Body).Decode(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func queryReaction(r *http.Request) *models.Reaction {
+	return &models.Reaction{
+		Nickname: r.URL.Query().Get("nickname"),
+		Reaction: r.URL.Query().Get("reaction"),
+	}
+}
@@ -0,0 +1,12 @@
+package reaction
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+type Repository interface {
+	Add(reaction *models.Reaction) error
+	Remove(reaction *models.Reaction) (bool, error)
+	ListForPost(id int64) ([]*models.Reaction, error)
+	ListForThread(id int64) ([]*models.Reaction, error)
+	CountForPosts(ids []int64) (map[int64]map[string]int64, error)
+	CountForThreads(ids []int64) (map[int64]map[string]int64, error)
+}
@@ -0,0 +1,144 @@
+package reaction_rep
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/reaction"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) reaction.Repository {
+	return &Repository{db}
+}
+
+// Add and Remove touch the modified time of the post or thread along with
+// the reaction, since the counts are part of it.
+func (r *Repository) Add(item *models.Reaction) error {
+	var err error
+	if item.Post != 0 {
+		_, err = r.db.Exec(
+			`WITH added AS (
+					INSERT INTO reactions (nickname, reaction, post) VALUES ($1, $2, $3)
+						ON CONFLICT (LOWER(nickname), reaction, post) WHERE post IS NOT NULL DO NOTHING
+						RETURNING post
+				)
+				UPDATE posts SET modified = now() WHERE id IN (SELECT post FROM added)`,
+			item.Nickname,
+			item.Reaction,
+			item.Post,
+		)
+	} else {
+		_, err = r.db.Exec(
+			`WITH added AS (
+					INSERT INTO reactions (nickname, reaction, thread) VALUES ($1, $2, $3)
+						ON CONFLICT (LOWER(nickname), reaction, thread) WHERE thread IS NOT NULL DO NOTHING
+						RETURNING thread
+				)
+				UPDATE threads SET modified = now() WHERE id IN (SELECT thread FROM added)`,
+			item.Nickname,
+			item.Reaction,
+			item.Thread,
+		)
+	}
+	return err
+}
+
+func (r *Repository) Remove(item *models.Reaction) (bool, error) {
+	var n int64
+	err := r.db.QueryRow(
+		`WITH removed AS (
+				DELETE FROM reactions WHERE LOWER(nickname) = LOWER($1) AND reaction = $2 AND
+					(($3 <> 0 AND post = $3) OR ($4 <> 0 AND thread = $4))
+					RETURNING post, thread
+			), touched_posts AS (
+				UPDATE posts SET modified = now() WHERE id IN (SELECT post FROM removed)
+			), touched_threads AS (
+				UPDATE threads SET modified = now() WHERE id IN (SELECT thread FROM removed)
+			)
+			SELECT COUNT(*) FROM removed`,
+		item.Nickname,
+		item.Reaction,
+		item.Post,
+		item.Thread,
+	).Scan(&n)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *Repository) ListForPost(id int64) ([]*models.Reaction, error) {
+	return r.list("SELECT id, nickname, reaction, COALESCE(post, 0), COALESCE(thread, 0), created " +
+		"FROM reactions WHERE post = $1 ORDER BY created, id", id)
+}
+
+func (r *Repository) ListForThread(id int64) ([]*models.Reaction, error) {
+	return r.list("SELECT id, nickname, reaction, COALESCE(post, 0), COALESCE(thread, 0), created " +
+		"FROM reactions WHERE thread = $1 ORDER BY created, id", id)
+}
+
+func (r *Repository) CountForPosts(ids []int64) (map[int64]map[string]int64, error) {
+	return r.count("SELECT post, reaction, COUNT(*) FROM reactions " +
+		"WHERE post = ANY($1) GROUP BY post, reaction", ids)
+}
+
+func (r *Repository) CountForThreads(ids []int64) (map[int64]map[string]int64, error) {
+	return r.count("SELECT thread, reaction, COUNT(*) FROM reactions " +
+		"WHERE thread = ANY($1) GROUP BY thread, reaction", ids)
+}
+
+func (r *Repository) list(query string, id int64) ([]*models.Reaction, error) {
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.Reaction, 0)
+	for rows.Next() {
+		item := new(models.Reaction)
+		if err := rows.Scan(&item.ID, &item.Nickname, &item.Reaction, &item.Post, &item.Thread, &item.Created); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, item)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) count(query string, ids []int64) (map[int64]map[string]int64, error) {
+	counts := make(map[int64]map[string]int64)
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var id, n int64
+		var name string
+		if err := rows.Scan(&id, &name, &n); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		if counts[id] == nil {
+			counts[id] = make(map[string]int64)
+		}
+		counts[id][name] = n
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
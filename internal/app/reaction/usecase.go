@@ -0,0 +1,23 @@
+package reaction
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+const (
+	NOT_ALLOWED = "Reaction is not allowed: "
+	NOT_FOUND = "Can't find such reaction"
+	USER_NOT_FOUND = "Can't find user by nickname: "
+	POST_NOT_FOUND = "Can't find such post"
+	THREAD_NOT_FOUND = "Can't find such thread"
+	UNAUTHORIZED = "Log in to react"
+	FORBIDDEN = "Reactions can only be changed by their author"
+)
+
+type Usecase interface {
+	AddToPost(id int64, reaction *models.Reaction) (map[string]int64, error)
+	RemoveFromPost(id int64, reaction *models.Reaction) (map[string]int64, error)
+	GetPostReactions(id int64) ([]*models.Reaction, error)
+
+	AddToThread(slugOrID string, reaction *models.Reaction) (map[string]int64, error)
+	RemoveFromThread(slugOrID string, reaction *models.Reaction) (map[string]int64, error)
+	GetThreadReactions(slugOrID string) ([]*models.Reaction, error)
+}
@@ -0,0 +1,145 @@
+package reaction_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/reaction"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+)
+
+type ReactionUcase struct {
+	repository	reaction.Repository
+	forumUcase	forum.Usecase
+	userRep		user.Repository
+	allowed		map[string]bool
+}
+
+func NewReactionUsecase(r reaction.Repository, fu forum.Usecase, ur user.Repository, allowed []string) reaction.Usecase {
+	set := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		set[name] = true
+	}
+
+	return &ReactionUcase{
+		repository: r,
+		forumUcase: fu,
+		userRep:	ur,
+		allowed:	set,
+	}
+}
+
+func (u *ReactionUcase) AddToPost(id int64, item *models.Reaction) (map[string]int64, error) {
+	post, err := u.forumUcase.FindPost(id)
+	if err != nil {
+		return nil, errors.New(reaction.POST_NOT_FOUND)
+	}
+
+	item.Post = post.ID
+	if err := u.add(item); err != nil {
+		return nil, err
+	}
+	return u.postCounts(post.ID)
+}
+
+func (u *ReactionUcase) RemoveFromPost(id int64, item *models.Reaction) (map[string]int64, error) {
+	post, err := u.forumUcase.FindPost(id)
+	if err != nil {
+		return nil, errors.New(reaction.POST_NOT_FOUND)
+	}
+
+	item.Post = post.ID
+	if err := u.remove(item); err != nil {
+		return nil, err
+	}
+	return u.postCounts(post.ID)
+}
+
+func (u *ReactionUcase) GetPostReactions(id int64) ([]*models.Reaction, error) {
+	post, err := u.forumUcase.FindPost(id)
+	if err != nil {
+		return nil, errors.New(reaction.POST_NOT_FOUND)
+	}
+	return u.repository.ListForPost(post.ID)
+}
+
+func (u *ReactionUcase) AddToThread(slugOrID string, item *models.Reaction) (map[string]int64, error) {
+	thread, err := u.forumUcase.GetThread(slugOrID)
+	if err != nil {
+		return nil, errors.New(reaction.THREAD_NOT_FOUND)
+	}
+
+	item.Thread = thread.ID
+	if err := u.add(item); err != nil {
+		return nil, err
+	}
+	return u.threadCounts(thread.ID)
+}
+
+func (u *ReactionUcase) RemoveFromThread(slugOrID string, item *models.Reaction) (map[string]int64, error) {
+	thread, err := u.forumUcase.GetThread(slugOrID)
+	if err != nil {
+		return nil, errors.New(reaction.THREAD_NOT_FOUND)
+	}
+
+	item.Thread = thread.ID
+	if err := u.remove(item); err != nil {
+		return nil, err
+	}
+	return u.threadCounts(thread.ID)
+}
+
+func (u *ReactionUcase) GetThreadReactions(slugOrID string) ([]*models.Reaction, error) {
+	thread, err := u.forumUcase.GetThread(slugOrID)
+	if err != nil {
+		return nil, errors.New(reaction.THREAD_NOT_FOUND)
+	}
+	return u.repository.ListForThread(thread.ID)
+}
+
+func (u *ReactionUcase) add(item *models.Reaction) error {
+	if !u.allowed[item.Reaction] {
+		return errors.New(reaction.NOT_ALLOWED + item.Reaction)
+	}
+
+	us, err := u.userRep.FindByName(item.Nickname)
+	if err != nil {
+		return errors.New(reaction.USER_NOT_FOUND + item.Nickname)
+	}
+	item.Nickname = us.Nickname
+
+	return errors.Wrap(u.repository.Add(item), "repository.Add()")
+}
+
+func (u *ReactionUcase) remove(item *models.Reaction) error {
+	removed, err := u.repository.Remove(item)
+	if err != nil {
+		return errors.Wrap(err, "repository.Remove()")
+	}
+	if !removed {
+		return errors.New(reaction.NOT_FOUND)
+	}
+	return nil
+}
+
+func (u *ReactionUcase) postCounts(id int64) (map[string]int64, error) {
+	counts, err := u.repository.CountForPosts([]int64{id})
+	if err != nil {
+		return nil, err
+	}
+	if counts[id] == nil {
+		return map[string]int64{}, nil
+	}
+	return counts[id], nil
+}
+
+func (u *ReactionUcase) threadCounts(id int64) (map[string]int64, error) {
+	counts, err := u.repository.CountForThreads([]int64{id})
+	if err != nil {
+		return nil, err
+	}
+	if counts[id] == nil {
+		return map[string]int64{}, nil
+	}
+	return counts[id], nil
+}
@@ -0,0 +1,196 @@
+package schedule_handler
+
+import (
+	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/efimovad/Forums.git/internal/app/schedule"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Handler struct {
+	usecase			schedule.Usecase
+	sessionStore	sessions.Store
+}
+
+func NewScheduleHandler(m *mux.Router, u schedule.Usecase, sessionStore sessions.Store) {
+	handler := &Handler{
+		usecase:		u,
+		sessionStore:   sessionStore,
+	}
+
+	m.HandleFunc("/api/forum/{slug}/schedule", handler.ScheduleThread).Methods(http.MethodPost)
+	m.HandleFunc("/api/thread/{slug_or_id}/schedule", handler.SchedulePost).Methods(http.MethodPost)
+	m.HandleFunc("/api/scheduled/{id}", handler.GetScheduled).Methods(http.MethodGet)
+	m.HandleFunc("/api/scheduled/{id}", handler.UpdateScheduled).Methods(http.MethodPost)
+	m.HandleFunc("/api/scheduled/{id}", handler.CancelScheduled).Methods(http.MethodDelete)
+	m.HandleFunc("/api/user/{nickname}/scheduled", handler.GetUserScheduled).Methods(http.MethodGet)
+}
+
+func (h *Handler) ScheduleThread(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	s, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.usecase.ScheduleThread(mux.Vars(r)["slug"], general.CurrentUser(r), s)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusCreated, res)
+}
+
+func (h *Handler) SchedulePost(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	s, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.usecase.SchedulePost(mux.Vars(r)["slug_or_id"], general.CurrentUser(r), s)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusCreated, res)
+}
+
+func (h *Handler) GetScheduled(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.Get(id, general.CurrentUser(r))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) UpdateScheduled(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	s, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.usecase.Update(id, general.CurrentUser(r), s)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) CancelScheduled(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.usecase.Cancel(id, general.CurrentUser(r))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *Handler) GetUserScheduled(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := new(models.ListParameters)
+	str := r.URL.Query().Get("limit")
+	if str != "" {
+		limit, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Limit = limit
+	}
+
+	str = r.URL.Query().Get("desc")
+	if str != "" {
+		desc, err := strconv.ParseBool(str)
+		if err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		params.Desc = desc
+	}
+
+	params.Since = r.URL.Query().Get("since")
+	if params.Since != "" {
+		if _, err := strconv.ParseInt(params.Since, 10, 64); err != nil {
+			general.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	list, err := h.usecase.List(mux.Vars(r)["nickname"], general.CurrentUser(r), params)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) decode(w http.ResponseWriter, r *http.Request) (*models.Scheduled, bool) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "ScheduleHandler.decode<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	s := new(models.Scheduled)
+	if err := json.NewDecoder(r.Body).Decode(s); err != nil {
+		err = errors.Wrapf(err, "ScheduleHandler.decode<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return nil, false
+	}
+	return s, true
+}
+
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, err error) {
+	if strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+	} else if err.Error() == schedule.UNAUTHORIZED {
+		general.Error(w, r, http.StatusUnauthorized, err)
+	} else if err.Error() == schedule.FORBIDDEN {
+		general.Error(w, r, http.StatusForbidden, err)
+	} else if err.Error() == schedule.NOT_PENDING || err.Error() == schedule.THREAD_CONFLICT ||
+		err.Error() == schedule.PARENT_POST_CONFLICT || err.Error() == forum.THREAD_MOVED {
+		general.Error(w, r, http.StatusConflict, err)
+	} else if err.Error() == schedule.WRONG_INPUT || err.Error() == forum.WRONG_TAGS {
+		general.Error(w, r, http.StatusBadRequest, err)
+	} else {
+		general.Error(w, r, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,24 @@
+package schedule
+
+import (
+	"github.com/efimovad/Forums.git/internal/models"
+	"time"
+)
+
+type Repository interface {
+	Create(s *models.Scheduled) error
+	Find(id int64) (*models.Scheduled, error)
+	Update(s *models.Scheduled) (bool, error)
+	Cancel(id int64) (bool, error)
+	List(nickname string, params *models.ListParameters) ([]*models.Scheduled, error)
+	// ClaimDue marks pending items whose publish time has come as being
+	// published and returns them, so that concurrent schedulers never
+	// publish the same item twice. Items claimed longer than CLAIM_LEASE
+	// ago are claimed again, with PreviousClaim set to the earlier claim.
+	ClaimDue(now time.Time) ([]*models.Scheduled, error)
+	// FindPublished returns the id of the thread or post an earlier attempt
+	// claimed at since already created for the item, or 0 if there is none.
+	FindPublished(s *models.Scheduled, since time.Time) (int64, error)
+	MarkPublished(id int64, published int64) error
+	MarkFailed(id int64, reason string) error
+}
@@ -0,0 +1,257 @@
+package schedule_rep
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/schedule"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/lib/pq"
+	"strconv"
+	"time"
+)
+
+const columns = "id, kind, author, forum, COALESCE(thread, 0), parent, title, slug, message, tags, " +
+	"publish_at, status, error, published, created"
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewScheduleRepository(db *sql.DB) schedule.Repository {
+	return &Repository{db}
+}
+
+func (r *Repository) Create(s *models.Scheduled) error {
+	var thread interface{}
+	if s.Thread != 0 {
+		thread = s.Thread
+	}
+
+	return r.db.QueryRow(
+		"INSERT INTO scheduled (kind, author, forum, thread, parent, title, slug, message, tags, publish_at) " +
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, status, created",
+		s.Kind,
+		s.Author,
+		s.Forum,
+		thread,
+		s.Parent,
+		s.Title,
+		s.Slug,
+		s.Message,
+		pq.Array(s.Tags),
+		s.PublishAt,
+	).Scan(&s.ID, &s.Status, &s.Created)
+}
+
+func (r *Repository) Find(id int64) (*models.Scheduled, error) {
+	return scan(r.db.QueryRow("SELECT " + columns + " FROM scheduled WHERE id = $1", id))
+}
+
+// Update only touches items that are still pending, so an edit racing with
+// the scheduler either lands before publishing or is refused.
+func (r *Repository) Update(s *models.Scheduled) (bool, error) {
+	res, err := r.db.Exec(
+		"UPDATE scheduled SET parent = $2, title = $3, slug = $4, message = $5, tags = $6, publish_at = $7 " +
+			"WHERE id = $1 AND status = $8",
+		s.ID,
+		s.Parent,
+		s.Title,
+		s.Slug,
+		s.Message,
+		pq.Array(s.Tags),
+		s.PublishAt,
+		schedule.STATUS_PENDING,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *Repository) Cancel(id int64) (bool, error) {
+	res, err := r.db.Exec(
+		"UPDATE scheduled SET status = $2 WHERE id = $1 AND status = $3",
+		id,
+		schedule.STATUS_CANCELLED,
+		schedule.STATUS_PENDING,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *Repository) List(nickname string, params *models.ListParameters) ([]*models.Scheduled, error) {
+	var since int64
+	if params.Since != "" {
+		var err error
+		if since, err = strconv.ParseInt(params.Since, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.Query(
+		`SELECT ` + columns + `
+				FROM scheduled
+				WHERE LOWER(author) = LOWER($1) AND
+					($2 = 0 OR (NOT $3 AND id > $2) OR ($3 AND id < $2))
+				ORDER BY
+					CASE WHEN $3 THEN id END DESC,
+					CASE WHEN NOT $3 THEN id END ASC
+				LIMIT CASE WHEN $4 > 0 THEN $4 END;`,
+		nickname, since, params.Desc, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows(rows)
+}
+
+func (r *Repository) ClaimDue(now time.Time) ([]*models.Scheduled, error) {
+	rows, err := r.db.Query(
+		`UPDATE scheduled SET status = $2, claimed = $1
+				FROM (
+					SELECT id AS claim_id, CASE WHEN status = $2 THEN claimed END AS previous_claim
+						FROM scheduled
+						WHERE (status = $3 AND publish_at <= $1) OR (status = $2 AND claimed <= $4)
+						ORDER BY publish_at, id
+						FOR UPDATE SKIP LOCKED
+				) c
+				WHERE id = c.claim_id
+				RETURNING ` + columns + `, c.previous_claim`,
+		now,
+		schedule.STATUS_PUBLISHING,
+		schedule.STATUS_PENDING,
+		now.Add(-schedule.CLAIM_LEASE),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.Scheduled, 0)
+	for rows.Next() {
+		var previous sql.NullTime
+		s, err := scan(rows, &previous)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		if previous.Valid {
+			s.PreviousClaim = &previous.Time
+		}
+		list = append(list, s)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// FindPublished matches on the fields publishing copies. A thread gets the
+// publish time as its created time; a post is created at publish time, so
+// after the earlier claim.
+func (r *Repository) FindPublished(s *models.Scheduled, since time.Time) (int64, error) {
+	var id int64
+	var err error
+	if s.Kind == schedule.KIND_THREAD {
+		err = r.db.QueryRow(
+			`SELECT id FROM threads
+					WHERE LOWER(author) = LOWER($1) AND title = $2 AND message = $3 AND created = $4
+					ORDER BY id LIMIT 1`,
+			s.Author,
+			s.Title,
+			s.Message,
+			s.PublishAt,
+		).Scan(&id)
+	} else {
+		err = r.db.QueryRow(
+			`SELECT id FROM posts
+					WHERE thread = $1 AND LOWER(author) = LOWER($2) AND parent = $3 AND message = $4 AND created >= $5
+					ORDER BY id LIMIT 1`,
+			s.Thread,
+			s.Author,
+			s.Parent,
+			s.Message,
+			since,
+		).Scan(&id)
+	}
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+func (r *Repository) MarkPublished(id int64, published int64) error {
+	_, err := r.db.Exec(
+		"UPDATE scheduled SET status = $2, published = $3, error = '' WHERE id = $1",
+		id,
+		schedule.STATUS_PUBLISHED,
+		published,
+	)
+	return err
+}
+
+func (r *Repository) MarkFailed(id int64, reason string) error {
+	_, err := r.db.Exec(
+		"UPDATE scheduled SET status = $2, error = $3 WHERE id = $1",
+		id,
+		schedule.STATUS_FAILED,
+		reason,
+	)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scan(row scanner, extra ...interface{}) (*models.Scheduled, error) {
+	s := new(models.Scheduled)
+	dest := []interface{}{
+		&s.ID,
+		&s.Kind,
+		&s.Author,
+		&s.Forum,
+		&s.Thread,
+		&s.Parent,
+		&s.Title,
+		&s.Slug,
+		&s.Message,
+		pq.Array(&s.Tags),
+		&s.PublishAt,
+		&s.Status,
+		&s.Error,
+		&s.Published,
+		&s.Created,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func scanRows(rows *sql.Rows) ([]*models.Scheduled, error) {
+	list := make([]*models.Scheduled, 0)
+	for rows.Next() {
+		s, err := scan(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, s)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,34 @@
+package schedule
+
+import (
+	"log"
+	"time"
+)
+
+// Start runs PublishDue every interval until the returned stop function is called.
+func Start(u Usecase, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				n, err := u.PublishDue(now)
+				if err != nil {
+					log.Println("schedule:", err)
+				}
+				if n > 0 {
+					log.Println("schedule: published", n)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
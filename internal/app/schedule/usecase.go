@@ -0,0 +1,50 @@
+package schedule
+
+import (
+	"github.com/efimovad/Forums.git/internal/models"
+	"time"
+)
+
+const (
+	KIND_THREAD = "thread"
+	KIND_POST = "post"
+)
+
+const (
+	STATUS_PENDING = "pending"
+	STATUS_PUBLISHING = "publishing"
+	STATUS_PUBLISHED = "published"
+	STATUS_FAILED = "failed"
+	STATUS_CANCELLED = "cancelled"
+)
+
+// CLAIM_LEASE is how long an item may stay claimed for publishing before
+// another scheduler takes it over, e.g. after the first one died.
+const CLAIM_LEASE = 10 * time.Minute
+
+const (
+	NOT_FOUND = "Can't find such scheduled item"
+	FORUM_NOT_FOUND = "Can't find such forum"
+	THREAD_NOT_FOUND = "Can't find such thread"
+	USER_NOT_FOUND = "Can't find user by nickname: "
+	PARENT_POST_CONFLICT = "Parent post was created in another thread"
+	THREAD_CONFLICT = "Such thread already exists"
+	NOT_PENDING = "Scheduled item was already published or cancelled"
+	FORBIDDEN = "Only the author can manage scheduled items"
+	UNAUTHORIZED = "Log in to schedule threads and posts"
+	WRONG_INPUT = "Scheduled item needs a message and a publish time in the future"
+	HELD = "Held for moderation: "
+)
+
+type Usecase interface {
+	ScheduleThread(forumSlug string, nickname string, s *models.Scheduled) (*models.Scheduled, error)
+	SchedulePost(slugOrID string, nickname string, s *models.Scheduled) (*models.Scheduled, error)
+	Get(id int64, nickname string) (*models.Scheduled, error)
+	Update(id int64, nickname string, s *models.Scheduled) (*models.Scheduled, error)
+	Cancel(id int64, nickname string) (*models.Scheduled, error)
+	List(nickname string, caller string, params *models.ListParameters) ([]*models.Scheduled, error)
+
+	// PublishDue publishes every item whose publish time has passed and
+	// returns the number of items published.
+	PublishDue(now time.Time) (int, error)
+}
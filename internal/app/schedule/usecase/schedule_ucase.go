@@ -0,0 +1,331 @@
+package schedule_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/schedule"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type ScheduleUcase struct {
+	repository	schedule.Repository
+	forumUcase	forum.Usecase
+	userRep		user.Repository
+}
+
+func NewScheduleUsecase(r schedule.Repository, fu forum.Usecase, ur user.Repository) schedule.Usecase {
+	return &ScheduleUcase{
+		repository: r,
+		forumUcase: fu,
+		userRep:	ur,
+	}
+}
+
+// ScheduleThread stores a thread to be created in the forum at PublishAt.
+// Nothing is written to the forum itself until then. nickname is the user
+// of the request's session, who becomes the author.
+func (u *ScheduleUcase) ScheduleThread(forumSlug string, nickname string, s *models.Scheduled) (*models.Scheduled, error) {
+	if nickname == "" {
+		return nil, errors.New(schedule.UNAUTHORIZED)
+	}
+
+	f, err := u.forumUcase.GetForum(forumSlug)
+	if err != nil {
+		return nil, errors.New(schedule.FORUM_NOT_FOUND)
+	}
+
+	s.Kind = schedule.KIND_THREAD
+	s.Author = nickname
+	s.Forum = f.Slug
+	s.Thread = 0
+	s.Parent = 0
+	if err := u.checkThread(s); err != nil {
+		return nil, err
+	}
+
+	return u.create(s)
+}
+
+// SchedulePost stores a post to be published in the thread at PublishAt.
+// Its path is computed only then, so it lands after everything posted in
+// the meantime.
+func (u *ScheduleUcase) SchedulePost(slugOrID string, nickname string, s *models.Scheduled) (*models.Scheduled, error) {
+	if nickname == "" {
+		return nil, errors.New(schedule.UNAUTHORIZED)
+	}
+
+	t, err := u.forumUcase.GetThread(slugOrID)
+	if err != nil {
+		return nil, errors.New(schedule.THREAD_NOT_FOUND)
+	}
+
+	if t.MovedTo != 0 {
+		return nil, errors.New(forum.THREAD_MOVED)
+	}
+
+	s.Kind = schedule.KIND_POST
+	s.Author = nickname
+	s.Forum = t.Forum
+	s.Thread = t.ID
+	s.Title = ""
+	s.Slug = ""
+	s.Tags = nil
+	if err := u.checkPost(s); err != nil {
+		return nil, err
+	}
+
+	return u.create(s)
+}
+
+func (u *ScheduleUcase) create(s *models.Scheduled) (*models.Scheduled, error) {
+	us, err := u.userRep.FindByName(s.Author)
+	if err != nil {
+		return nil, errors.New(schedule.USER_NOT_FOUND + s.Author)
+	}
+	s.Author = us.Nickname
+
+	if err := u.repository.Create(s); err != nil {
+		return nil, errors.Wrap(err, "repository.Create()")
+	}
+	return s, nil
+}
+
+func (u *ScheduleUcase) Get(id int64, nickname string) (*models.Scheduled, error) {
+	return u.find(id, nickname)
+}
+
+// Update changes a pending item. Fields left empty in the request keep
+// their scheduled values.
+func (u *ScheduleUcase) Update(id int64, nickname string, s *models.Scheduled) (*models.Scheduled, error) {
+	old, err := u.find(id, nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	if old.Status != schedule.STATUS_PENDING {
+		return nil, errors.New(schedule.NOT_PENDING)
+	}
+
+	if s.Message != "" {
+		old.Message = s.Message
+	}
+	if !s.PublishAt.IsZero() {
+		old.PublishAt = s.PublishAt
+	}
+
+	if old.Kind == schedule.KIND_THREAD {
+		if s.Title != "" {
+			old.Title = s.Title
+		}
+		if s.Slug != "" {
+			old.Slug = s.Slug
+		}
+		if s.Tags != nil {
+			old.Tags = s.Tags
+		}
+		err = u.checkThread(old)
+	} else {
+		if s.Parent != 0 {
+			old.Parent = s.Parent
+		}
+		err = u.checkPost(old)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := u.repository.Update(old)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.Update()")
+	}
+	if !updated {
+		return nil, errors.New(schedule.NOT_PENDING)
+	}
+	return old, nil
+}
+
+func (u *ScheduleUcase) Cancel(id int64, nickname string) (*models.Scheduled, error) {
+	s, err := u.find(id, nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled, err := u.repository.Cancel(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.Cancel()")
+	}
+	if !cancelled {
+		return nil, errors.New(schedule.NOT_PENDING)
+	}
+
+	s.Status = schedule.STATUS_CANCELLED
+	return s, nil
+}
+
+// List shows a user's scheduled items. They are not public until published,
+// so only their author may list them.
+func (u *ScheduleUcase) List(nickname string, caller string, params *models.ListParameters) ([]*models.Scheduled, error) {
+	us, err := u.userRep.FindByName(nickname)
+	if err != nil {
+		return nil, errors.New(schedule.USER_NOT_FOUND + nickname)
+	}
+
+	if !strings.EqualFold(us.Nickname, caller) {
+		return nil, errors.New(schedule.FORBIDDEN)
+	}
+
+	list, err := u.repository.List(us.Nickname, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.List()")
+	}
+	return list, nil
+}
+
+// PublishDue goes through the regular thread and post creation, so paths,
+// forum counters, notifications and subscriptions are all computed at
+// publish time. An item that can't be published, or that the content
+// filters hold for moderation, is marked as failed with the reason and
+// doesn't stop the others.
+//
+// An item claimed again after its lease ran out is first looked up in the
+// forum, so a scheduler that died between creating it and marking it
+// published doesn't get it published twice. Held items leave nothing to
+// find: those may be queued for moderation more than once.
+func (u *ScheduleUcase) PublishDue(now time.Time) (int, error) {
+	due, err := u.repository.ClaimDue(now)
+	if err != nil {
+		return 0, errors.Wrap(err, "repository.ClaimDue()")
+	}
+
+	published := 0
+	for _, s := range due {
+		if s.PreviousClaim != nil {
+			id, err := u.repository.FindPublished(s, *s.PreviousClaim)
+			if err != nil {
+				return published, errors.Wrap(err, "repository.FindPublished()")
+			}
+			if id != 0 {
+				if err := u.repository.MarkPublished(s.ID, id); err != nil {
+					return published, errors.Wrap(err, "repository.MarkPublished()")
+				}
+				published++
+				continue
+			}
+		}
+
+		id, err := u.publish(s)
+		if err != nil {
+			log.Println(errors.Wrap(err, "schedule: publish "+strconv.FormatInt(s.ID, 10)))
+			if err := u.repository.MarkFailed(s.ID, err.Error()); err != nil {
+				return published, errors.Wrap(err, "repository.MarkFailed()")
+			}
+			continue
+		}
+
+		if err := u.repository.MarkPublished(s.ID, id); err != nil {
+			return published, errors.Wrap(err, "repository.MarkPublished()")
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+func (u *ScheduleUcase) publish(s *models.Scheduled) (int64, error) {
+	if s.Kind == schedule.KIND_THREAD {
+		t := &models.Thread{
+			Forum:		s.Forum,
+			Author:		s.Author,
+			Created:	s.PublishAt,
+			Title:		s.Title,
+			Slug:		s.Slug,
+			Message:	s.Message,
+			Tags:		s.Tags,
+		}
+		if _, err := u.forumUcase.CreateThread(t); err != nil {
+			return 0, err
+		}
+		if t.Moderation != nil && t.ID == 0 {
+			return 0, errors.New(schedule.HELD + t.Moderation.Reason)
+		}
+		return t.ID, nil
+	}
+
+	p := &models.Post{
+		Author:		s.Author,
+		Parent:		s.Parent,
+		Message:	s.Message,
+	}
+	if err := u.forumUcase.CreatePosts(strconv.FormatInt(s.Thread, 10), []*models.Post{p}); err != nil {
+		return 0, err
+	}
+	if p.Moderation != nil && p.ID == 0 {
+		return 0, errors.New(schedule.HELD + p.Moderation.Reason)
+	}
+	return p.ID, nil
+}
+
+func (u *ScheduleUcase) find(id int64, nickname string) (*models.Scheduled, error) {
+	s, err := u.repository.Find(id)
+	if err != nil {
+		return nil, errors.New(schedule.NOT_FOUND)
+	}
+
+	if !strings.EqualFold(s.Author, nickname) {
+		return nil, errors.New(schedule.FORBIDDEN)
+	}
+	return s, nil
+}
+
+func (u *ScheduleUcase) checkThread(s *models.Scheduled) error {
+	if err := checkCommon(s); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(s.Title) == "" {
+		return errors.New(schedule.WRONG_INPUT)
+	}
+
+	if len(s.Tags) > forum.MAX_TAGS {
+		return errors.New(forum.WRONG_TAGS)
+	}
+	for _, tag := range s.Tags {
+		if utf8.RuneCountInString(tag) > forum.MAX_TAG_LENGTH {
+			return errors.New(forum.WRONG_TAGS)
+		}
+	}
+
+	if s.Slug != "" {
+		if _, err := u.forumUcase.GetThread(s.Slug); err == nil {
+			return errors.New(schedule.THREAD_CONFLICT)
+		}
+	}
+	return nil
+}
+
+func (u *ScheduleUcase) checkPost(s *models.Scheduled) error {
+	if err := checkCommon(s); err != nil {
+		return err
+	}
+
+	if s.Parent != 0 {
+		parent, err := u.forumUcase.FindPost(s.Parent)
+		if err != nil || parent.Thread != s.Thread {
+			return errors.New(schedule.PARENT_POST_CONFLICT)
+		}
+	}
+	return nil
+}
+
+func checkCommon(s *models.Scheduled) error {
+	if strings.TrimSpace(s.Message) == "" || !s.PublishAt.After(time.Now()) {
+		return errors.New(schedule.WRONG_INPUT)
+	}
+	return nil
+}
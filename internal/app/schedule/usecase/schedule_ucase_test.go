@@ -0,0 +1,136 @@
+package schedule_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/schedule"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"testing"
+	"time"
+)
+
+type fakeRepository struct {
+	schedule.Repository
+	due			[]*models.Scheduled
+	existing	map[int64]int64
+	published	map[int64]int64
+	failed		map[int64]string
+}
+
+func newFakeRepository(due ...*models.Scheduled) *fakeRepository {
+	return &fakeRepository{
+		due:		due,
+		existing:	make(map[int64]int64),
+		published:	make(map[int64]int64),
+		failed:		make(map[int64]string),
+	}
+}
+
+func (r *fakeRepository) ClaimDue(now time.Time) ([]*models.Scheduled, error) {
+	due := r.due
+	r.due = nil
+	return due, nil
+}
+
+func (r *fakeRepository) FindPublished(s *models.Scheduled, since time.Time) (int64, error) {
+	return r.existing[s.ID], nil
+}
+
+func (r *fakeRepository) MarkPublished(id int64, published int64) error {
+	r.published[id] = published
+	return nil
+}
+
+func (r *fakeRepository) MarkFailed(id int64, reason string) error {
+	r.failed[id] = reason
+	return nil
+}
+
+type fakeForum struct {
+	forum.Usecase
+	threads	[]*models.Thread
+	posts	[]*models.Post
+	hold	bool
+}
+
+func (f *fakeForum) CreateThread(t *models.Thread) (*models.Thread, error) {
+	if f.hold {
+		t.Moderation = &models.FilterVerdict{Reason: "spam"}
+		return t, nil
+	}
+	f.threads = append(f.threads, t)
+	t.ID = int64(100 + len(f.threads))
+	return t, nil
+}
+
+func (f *fakeForum) CreatePosts(slugOrID string, posts []*models.Post) error {
+	if slugOrID == "0" {
+		return errors.New(forum.THREAD_NOT_FOUND)
+	}
+	for _, p := range posts {
+		f.posts = append(f.posts, p)
+		p.ID = int64(200 + len(f.posts))
+	}
+	return nil
+}
+
+func TestPublishDue(t *testing.T) {
+	rep := newFakeRepository(
+		&models.Scheduled{ID: 1, Kind: schedule.KIND_THREAD, Author: "a", Forum: "f", Title: "t", Message: "m"},
+		&models.Scheduled{ID: 2, Kind: schedule.KIND_POST, Author: "a", Thread: 7, Message: "m"},
+		&models.Scheduled{ID: 3, Kind: schedule.KIND_POST, Author: "a", Message: "m"},
+	)
+	fu := new(fakeForum)
+	u := NewScheduleUsecase(rep, fu, nil)
+
+	n, err := u.PublishDue(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("published %d items, want 2", n)
+	}
+	if rep.published[1] != 101 || rep.published[2] != 201 {
+		t.Errorf("published ids: %v", rep.published)
+	}
+	if _, ok := rep.failed[3]; !ok {
+		t.Error("post to a missing thread isn't marked failed")
+	}
+}
+
+func TestPublishDueHeld(t *testing.T) {
+	rep := newFakeRepository(&models.Scheduled{ID: 1, Kind: schedule.KIND_THREAD, Author: "a", Title: "t", Message: "m"})
+	u := NewScheduleUsecase(rep, &fakeForum{hold: true}, nil)
+
+	if n, err := u.PublishDue(time.Now()); err != nil || n != 0 {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if rep.failed[1] != schedule.HELD + "spam" {
+		t.Errorf("got failure %q", rep.failed[1])
+	}
+}
+
+func TestPublishDueReclaimed(t *testing.T) {
+	claimed := time.Now().Add(-time.Hour)
+	rep := newFakeRepository(
+		&models.Scheduled{ID: 1, Kind: schedule.KIND_POST, Author: "a", Thread: 7, Message: "m", PreviousClaim: &claimed},
+		&models.Scheduled{ID: 2, Kind: schedule.KIND_POST, Author: "a", Thread: 7, Message: "m", PreviousClaim: &claimed},
+	)
+	rep.existing[1] = 55
+	fu := new(fakeForum)
+	u := NewScheduleUsecase(rep, fu, nil)
+
+	n, err := u.PublishDue(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("published %d items, want 2", n)
+	}
+	if rep.published[1] != 55 {
+		t.Errorf("reclaimed item got %d, want the earlier post 55", rep.published[1])
+	}
+	if len(fu.posts) != 1 || rep.published[2] != 201 {
+		t.Errorf("reclaimed item without a post has to be published once, got %d posts", len(fu.posts))
+	}
+}
@@ -1,12 +1,48 @@
 package app
 
 import (
+	attachment_handler "github.com/efimovad/Forums.git/internal/app/attachment/delivery/http"
+	attachment_rep "github.com/efimovad/Forums.git/internal/app/attachment/repository"
+	attachment_ucase "github.com/efimovad/Forums.git/internal/app/attachment/usecase"
+	"github.com/efimovad/Forums.git/internal/app/blob"
+	bookmark_handler "github.com/efimovad/Forums.git/internal/app/bookmark/delivery/http"
+	bookmark_rep "github.com/efimovad/Forums.git/internal/app/bookmark/repository"
+	bookmark_ucase "github.com/efimovad/Forums.git/internal/app/bookmark/usecase"
+	"github.com/efimovad/Forums.git/internal/app/cache"
+	"github.com/efimovad/Forums.git/internal/app/digest"
+	digest_rep "github.com/efimovad/Forums.git/internal/app/digest/repository"
+	digest_ucase "github.com/efimovad/Forums.git/internal/app/digest/usecase"
+	draft_handler "github.com/efimovad/Forums.git/internal/app/draft/delivery/http"
+	draft_rep "github.com/efimovad/Forums.git/internal/app/draft/repository"
+	draft_ucase "github.com/efimovad/Forums.git/internal/app/draft/usecase"
+	"github.com/efimovad/Forums.git/internal/app/filter"
+	filter_rep "github.com/efimovad/Forums.git/internal/app/filter/repository"
 	forum_handler "github.com/efimovad/Forums.git/internal/app/forum/delivery/http"
 	forum_rep "github.com/efimovad/Forums.git/internal/app/forum/repository"
 	forum_ucase "github.com/efimovad/Forums.git/internal/app/forum/usecase"
+	"github.com/efimovad/Forums.git/internal/app/general"
 	general_handler "github.com/efimovad/Forums.git/internal/app/general/delivery/http"
 	general_rep "github.com/efimovad/Forums.git/internal/app/general/repository"
 	general_ucase "github.com/efimovad/Forums.git/internal/app/general/usecase"
+	"github.com/efimovad/Forums.git/internal/app/mailer"
+	"github.com/efimovad/Forums.git/internal/app/markdown"
+	notification_handler "github.com/efimovad/Forums.git/internal/app/notification/delivery/http"
+	notification_rep "github.com/efimovad/Forums.git/internal/app/notification/repository"
+	notification_ucase "github.com/efimovad/Forums.git/internal/app/notification/usecase"
+	poll_handler "github.com/efimovad/Forums.git/internal/app/poll/delivery/http"
+	poll_rep "github.com/efimovad/Forums.git/internal/app/poll/repository"
+	poll_ucase "github.com/efimovad/Forums.git/internal/app/poll/usecase"
+	reaction_handler "github.com/efimovad/Forums.git/internal/app/reaction/delivery/http"
+	reaction_rep "github.com/efimovad/Forums.git/internal/app/reaction/repository"
+	reaction_ucase "github.com/efimovad/Forums.git/internal/app/reaction/usecase"
+	"github.com/efimovad/Forums.git/internal/app/schedule"
+	schedule_handler "github.com/efimovad/Forums.git/internal/app/schedule/delivery/http"
+	schedule_rep "github.com/efimovad/Forums.git/internal/app/schedule/repository"
+	schedule_ucase "github.com/efimovad/Forums.git/internal/app/schedule/usecase"
+	subscription_handler "github.com/efimovad/Forums.git/internal/app/subscription/delivery/http"
+	subscription_rep "github.com/efimovad/Forums.git/internal/app/subscription/repository"
+	subscription_ucase "github.com/efimovad/Forums.git/internal/app/subscription/usecase"
+	"github.com/efimovad/Forums.git/internal/app/ratelimit"
 	user_handler "github.com/efimovad/Forums.git/internal/app/user/delivery/http"
 	user_rep "github.com/efimovad/Forums.git/internal/app/user/repository"
 	user_ucase "github.com/efimovad/Forums.git/internal/app/user/usecase"
@@ -16,6 +52,7 @@ import (
 	"github.com/pkg/errors"
 	"log"
 	"net/http"
+	"os"
 )
 
 type Server struct {
@@ -33,21 +70,88 @@ func (s *Server) configure() error{
 	if err != nil {
 		return errors.Wrap(err, "myStore.New()")
 	}
-	userRep := user_rep.NewUserRepository(myStore)
-	generalRep := general_rep.NewGeneralRepository(myStore)
-	forumRep := forum_rep.NewForumRepository(myStore)
+	lookupCache := cache.NewLRU(s.config.CacheSize, s.config.CacheTTL)
+
+	userRep := cache.NewUserRepository(user_rep.NewUserRepository(myStore), lookupCache)
+	generalRep := cache.NewGeneralRepository(general_rep.NewGeneralRepository(myStore), lookupCache)
+	forumRep := cache.NewForumRepository(forum_rep.NewForumRepository(myStore), lookupCache)
+	reactionRep := cache.NewReactionRepository(reaction_rep.NewReactionRepository(myStore), lookupCache)
+	pollRep := cache.NewPollRepository(poll_rep.NewPollRepository(myStore), lookupCache)
+	filterRep := filter_rep.NewFilterRepository(myStore)
+	notificationRep := notification_rep.NewNotificationRepository(myStore)
+	subscriptionRep := subscription_rep.NewSubscriptionRepository(myStore)
+	bookmarkRep := bookmark_rep.NewBookmarkRepository(myStore)
+	attachmentRep := attachment_rep.NewAttachmentRepository(myStore)
+	draftRep := draft_rep.NewDraftRepository(myStore)
+	scheduleRep := schedule_rep.NewScheduleRepository(myStore)
+
+	blobStore, err := blob.NewFileStore(s.config.AttachmentDir)
+	if err != nil {
+		return errors.Wrap(err, "blob.NewFileStore()")
+	}
 
 	userUcase := user_ucase.NewUserUsecase(userRep)
 	generalUcase := general_ucase.NewGeneralUsecase(generalRep)
-	forumUcase := forum_ucase.NewForumUsecase(forumRep, userRep)
+	notificationUcase := notification_ucase.NewNotificationUsecase(notificationRep, userRep, subscriptionRep)
+	renderer := markdown.NewRenderer(cache.NewLRU(s.config.RenderCacheSize, 0))
+	pipeline := filter.NewPipeline(
+		filter.BannedFilter{},
+		filter.LinkFilter{},
+		filter.NewDuplicateFilter(filterRep),
+		filter.NewNewAccountFilter(filterRep),
+	)
+	forumUcase := forum_ucase.NewForumUsecase(forumRep, userRep, reactionRep, notificationUcase, subscriptionRep, pollRep, renderer,
+		filterRep, pipeline, s.config.Filters, s.config.Moderators)
+	reactionUcase := reaction_ucase.NewReactionUsecase(reactionRep, forumUcase, userRep, s.config.Reactions)
+	pollUcase := poll_ucase.NewPollUsecase(pollRep, forumUcase, userRep)
+	subscriptionUcase := subscription_ucase.NewSubscriptionUsecase(subscriptionRep, forumUcase, userRep)
+	bookmarkUcase := bookmark_ucase.NewBookmarkUsecase(bookmarkRep, forumRep, userRep)
+	attachmentUcase := attachment_ucase.NewAttachmentUsecase(attachmentRep, forumUcase, blobStore,
+		s.config.AttachmentMaxSize, s.config.AttachmentMaxCount, s.config.AttachmentTypes)
+	draftUcase := draft_ucase.NewDraftUsecase(draftRep, forumUcase, userRep)
+	scheduleUcase := schedule_ucase.NewScheduleUsecase(scheduleRep, forumUcase, userRep)
 
 	user_handler.NewUserHandler(s.mux, userUcase, s.sessionStore)
 	general_handler.NewGeneralHandler(s.mux, generalUcase, s.sessionStore)
+	attachment_handler.NewAttachmentHandler(s.mux, attachmentUcase, s.sessionStore)
 	forum_handler.NewForumHandler(s.mux, forumUcase, s.sessionStore)
+	reaction_handler.NewReactionHandler(s.mux, reactionUcase, s.sessionStore)
+	poll_handler.NewPollHandler(s.mux, pollUcase, s.sessionStore)
+	notification_handler.NewNotificationHandler(s.mux, notificationUcase, s.sessionStore)
+	subscription_handler.NewSubscriptionHandler(s.mux, subscriptionUcase, s.sessionStore)
+	bookmark_handler.NewBookmarkHandler(s.mux, bookmarkUcase, s.sessionStore)
+	draft_handler.NewDraftHandler(s.mux, draftUcase, s.sessionStore)
+	schedule_handler.NewScheduleHandler(s.mux, scheduleUcase, s.sessionStore)
+	cache.NewCacheHandler(s.mux, lookupCache)
+
+	s.mux.Use(general.SessionMiddleware(s.sessionStore, userRep.FindByName))
+	s.mux.Use(ratelimit.Middleware(ratelimit.NewMemoryStore(s.config.RateIdle), s.config.RateLimit))
+
+	m, err := s.newMailer()
+	if err != nil {
+		return errors.Wrap(err, "newMailer()")
+	}
+	digest.Start(digest_ucase.NewDigestUsecase(digest_rep.NewDigestRepository(myStore), m), s.config.DigestInterval)
+	schedule.Start(scheduleUcase, s.config.ScheduleInterval)
 
 	return nil
 }
 
+func (s *Server) newMailer() (mailer.Mailer, error) {
+	if s.config.Mailer == "smtp" {
+		return mailer.NewSMTPMailer(s.config.SMTPAddr, s.config.SMTPUser, s.config.SMTPPassword, s.config.MailFrom)
+	}
+
+	if s.config.MailLog == "" {
+		return mailer.NewLogMailer(os.Stdout), nil
+	}
+	file, err := os.OpenFile(s.config.MailLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return mailer.NewLogMailer(file), nil
+}
+
 func NewServer() *Server{
 	config := NewConfig()
 	return &Server{
@@ -0,0 +1,135 @@
+package subscription_handler
+
+import (
+	"encoding/json"
+	"github.com/efimovad/Forums.git/internal/app/general"
+	"github.com/efimovad/Forums.git/internal/app/subscription"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type Handler struct {
+	usecase			subscription.Usecase
+	sessionStore	sessions.Store
+}
+
+func NewSubscriptionHandler(m *mux.Router, u subscription.Usecase, sessionStore sessions.Store) {
+	handler := &Handler{
+		usecase:		u,
+		sessionStore:   sessionStore,
+	}
+
+	m.HandleFunc("/api/thread/{slug_or_id}/subscribe", handler.SubscribeThread).Methods(http.MethodPost)
+	m.HandleFunc("/api/thread/{slug_or_id}/subscribe", handler.UnsubscribeThread).Methods(http.MethodDelete)
+	m.HandleFunc("/api/forum/{slug}/subscribe", handler.SubscribeForum).Methods(http.MethodPost)
+	m.HandleFunc("/api/forum/{slug}/subscribe", handler.UnsubscribeForum).Methods(http.MethodDelete)
+	m.HandleFunc("/api/user/{nickname}/subscriptions", handler.GetSubscriptions).Methods(http.MethodGet)
+}
+
+func (h *Handler) SubscribeThread(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	body, err := decodeSubscription(r)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, errors.Wrap(err, "SubscriptionHandler.SubscribeThread<-Decode()"))
+		return
+	}
+	// Subscriptions are always the caller's; a nickname in the body may
+	// only repeat it.
+	caller := general.CurrentUser(r)
+	if body.Nickname != "" && !strings.EqualFold(body.Nickname, caller) {
+		general.Error(w, r, http.StatusForbidden, errors.New(subscription.FORBIDDEN))
+		return
+	}
+
+	sub, err := h.usecase.SubscribeThread(mux.Vars(r)["slug_or_id"], caller)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusCreated, sub)
+}
+
+func (h *Handler) UnsubscribeThread(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	err := h.usecase.UnsubscribeThread(mux.Vars(r)["slug_or_id"], general.CurrentUser(r))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) SubscribeForum(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	body, err := decodeSubscription(r)
+	if err != nil {
+		general.Error(w, r, http.StatusBadRequest, errors.Wrap(err, "SubscriptionHandler.SubscribeForum<-Decode()"))
+		return
+	}
+	// Subscriptions are always the caller's; a nickname in the body may
+	// only repeat it.
+	caller := general.CurrentUser(r)
+	if body.Nickname != "" && !strings.EqualFold(body.Nickname, caller) {
+		general.Error(w, r, http.StatusForbidden, errors.New(subscription.FORBIDDEN))
+		return
+	}
+
+	sub, err := h.usecase.SubscribeForum(mux.Vars(r)["slug"], caller)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusCreated, sub)
+}
+
+func (h *Handler) UnsubscribeForum(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	err := h.usecase.UnsubscribeForum(mux.Vars(r)["slug"], general.CurrentUser(r))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	list, err := h.usecase.List(mux.Vars(r)["nickname"], general.CurrentUser(r))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	general.Respond(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, err error) {
+	if err.Error() == subscription.UNAUTHORIZED {
+		general.Error(w, r, http.StatusUnauthorized, err)
+	} else if err.Error() == subscription.FORBIDDEN {
+		general.Error(w, r, http.StatusForbidden, err)
+	} else if strings.Contains(err.Error(), "Can't find") {
+		general.Error(w, r, http.StatusNotFound, err)
+	} else {
+		general.Error(w, r, http.StatusInternalServerError, err)
+	}
+}
+
+func decodeSubscription(r *http.Request) (*models.Subscription, error) {
+	defer r.Body.Close()
+
+	sub := new(models.Subscription)
+	if err := json.NewDecoder(r.Body).Decode(sub); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return sub, nil
+}
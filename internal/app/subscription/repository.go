@@ -0,0 +1,12 @@
+package subscription
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+type Repository interface {
+	Subscribe(sub *models.Subscription) error
+	Unsubscribe(sub *models.Subscription) (bool, error)
+	List(nickname string) ([]*models.Subscription, error)
+
+	ThreadSubscribers(thread int64) ([]string, error)
+	ForumSubscribers(forum string) ([]string, error)
+}
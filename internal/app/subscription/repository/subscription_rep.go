@@ -0,0 +1,116 @@
+package subscription_rep
+
+import (
+	"database/sql"
+	"github.com/efimovad/Forums.git/internal/app/subscription"
+	"github.com/efimovad/Forums.git/internal/models"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewSubscriptionRepository(db *sql.DB) subscription.Repository {
+	return &Repository{db}
+}
+
+// Subscribe is idempotent: subscribing twice returns the first subscription.
+func (r *Repository) Subscribe(sub *models.Subscription) error {
+	if sub.Thread != 0 {
+		return r.db.QueryRow(
+			"INSERT INTO subscriptions (nickname, thread) VALUES ($1, $2) " +
+				"ON CONFLICT (LOWER(nickname), thread) WHERE thread IS NOT NULL " +
+				"DO UPDATE SET thread = EXCLUDED.thread RETURNING id, created",
+			sub.Nickname,
+			sub.Thread,
+		).Scan(&sub.ID, &sub.Created)
+	}
+
+	return r.db.QueryRow(
+		"INSERT INTO subscriptions (nickname, forum_id) VALUES ($1, $2) " +
+			"ON CONFLICT (LOWER(nickname), forum_id) WHERE forum_id IS NOT NULL " +
+			"DO UPDATE SET forum_id = EXCLUDED.forum_id RETURNING id, created",
+		sub.Nickname,
+		sub.ForumID,
+	).Scan(&sub.ID, &sub.Created)
+}
+
+func (r *Repository) Unsubscribe(sub *models.Subscription) (bool, error) {
+	res, err := r.db.Exec(
+		"DELETE FROM subscriptions WHERE LOWER(nickname) = LOWER($1) AND " +
+			"(($2 <> 0 AND thread = $2) OR ($3 <> 0 AND forum_id = $3))",
+		sub.Nickname,
+		sub.Thread,
+		sub.ForumID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *Repository) List(nickname string) ([]*models.Subscription, error) {
+	rows, err := r.db.Query(
+		`SELECT s.id, s.nickname, COALESCE(s.thread, 0), COALESCE(s.forum_id, 0), COALESCE(f.slug, ''), s.created
+				FROM subscriptions s
+				LEFT JOIN forums f ON f.id = s.forum_id
+				WHERE LOWER(s.nickname) = LOWER($1)
+				ORDER BY s.created, s.id`,
+		nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.Subscription, 0)
+	for rows.Next() {
+		sub := new(models.Subscription)
+		if err := rows.Scan(&sub.ID, &sub.Nickname, &sub.Thread, &sub.ForumID, &sub.Forum, &sub.Created); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, sub)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) ThreadSubscribers(thread int64) ([]string, error) {
+	return r.nicknames("SELECT nickname FROM subscriptions WHERE thread = $1", thread)
+}
+
+func (r *Repository) ForumSubscribers(forum string) ([]string, error) {
+	return r.nicknames(
+		"SELECT s.nickname FROM subscriptions s JOIN forums f ON f.id = s.forum_id " +
+			"WHERE LOWER(f.slug) = LOWER($1)",
+		forum)
+}
+
+func (r *Repository) nicknames(query string, arg interface{}) ([]string, error) {
+	rows, err := r.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []string
+	for rows.Next() {
+		var nickname string
+		if err := rows.Scan(&nickname); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		list = append(list, nickname)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,20 @@
+package subscription
+
+import "github.com/efimovad/Forums.git/internal/models"
+
+const (
+	NOT_FOUND = "Can't find such subscription"
+	USER_NOT_FOUND = "Can't find user by nickname: "
+	FORUM_NOT_FOUND = "Can't find forum by slug: "
+	THREAD_NOT_FOUND = "Can't find thread: "
+	UNAUTHORIZED = "Log in to manage subscriptions"
+	FORBIDDEN = "Subscriptions can only be managed by their owner"
+)
+
+type Usecase interface {
+	SubscribeThread(currThread string, nickname string) (*models.Subscription, error)
+	UnsubscribeThread(currThread string, nickname string) error
+	SubscribeForum(slug string, nickname string) (*models.Subscription, error)
+	UnsubscribeForum(slug string, nickname string) error
+	List(nickname string, caller string) ([]*models.Subscription, error)
+}
@@ -0,0 +1,132 @@
+package subscription_ucase
+
+import (
+	"github.com/efimovad/Forums.git/internal/app/forum"
+	"github.com/efimovad/Forums.git/internal/app/subscription"
+	"github.com/efimovad/Forums.git/internal/app/user"
+	"github.com/efimovad/Forums.git/internal/models"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+type SubscriptionUcase struct {
+	repository	subscription.Repository
+	forumUcase	forum.Usecase
+	userRep		user.Repository
+}
+
+func NewSubscriptionUsecase(r subscription.Repository, fu forum.Usecase, ur user.Repository) subscription.Usecase {
+	return &SubscriptionUcase{
+		repository: r,
+		forumUcase: fu,
+		userRep:	ur,
+	}
+}
+
+func (u *SubscriptionUcase) SubscribeThread(currThread string, nickname string) (*models.Subscription, error) {
+	sub, err := u.threadSubscription(currThread, nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repository.Subscribe(sub); err != nil {
+		return nil, errors.Wrap(err, "repository.Subscribe()")
+	}
+	return sub, nil
+}
+
+func (u *SubscriptionUcase) UnsubscribeThread(currThread string, nickname string) error {
+	sub, err := u.threadSubscription(currThread, nickname)
+	if err != nil {
+		return err
+	}
+	return u.unsubscribe(sub)
+}
+
+func (u *SubscriptionUcase) SubscribeForum(slug string, nickname string) (*models.Subscription, error) {
+	sub, err := u.forumSubscription(slug, nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repository.Subscribe(sub); err != nil {
+		return nil, errors.Wrap(err, "repository.Subscribe()")
+	}
+	return sub, nil
+}
+
+func (u *SubscriptionUcase) UnsubscribeForum(slug string, nickname string) error {
+	sub, err := u.forumSubscription(slug, nickname)
+	if err != nil {
+		return err
+	}
+	return u.unsubscribe(sub)
+}
+
+func (u *SubscriptionUcase) List(nickname string, caller string) ([]*models.Subscription, error) {
+	if caller == "" {
+		return nil, errors.New(subscription.UNAUTHORIZED)
+	}
+
+	us, err := u.userRep.FindByName(nickname)
+	if err != nil {
+		return nil, errors.New(subscription.USER_NOT_FOUND + nickname)
+	}
+
+	if !strings.EqualFold(us.Nickname, caller) {
+		return nil, errors.New(subscription.FORBIDDEN)
+	}
+
+	list, err := u.repository.List(us.Nickname)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository.List()")
+	}
+	return list, nil
+}
+
+func (u *SubscriptionUcase) threadSubscription(currThread string, nickname string) (*models.Subscription, error) {
+	if nickname == "" {
+		return nil, errors.New(subscription.UNAUTHORIZED)
+	}
+
+	us, err := u.userRep.FindByName(nickname)
+	if err != nil {
+		return nil, errors.New(subscription.USER_NOT_FOUND + nickname)
+	}
+
+	thread, err := u.forumUcase.GetThread(currThread)
+	if err != nil {
+		return nil, errors.New(subscription.THREAD_NOT_FOUND + currThread)
+	}
+
+	return &models.Subscription{Nickname: us.Nickname, Thread: thread.ID}, nil
+}
+
+func (u *SubscriptionUcase) forumSubscription(slug string, nickname string) (*models.Subscription, error) {
+	if nickname == "" {
+		return nil, errors.New(subscription.UNAUTHORIZED)
+	}
+
+	us, err := u.userRep.FindByName(nickname)
+	if err != nil {
+		return nil, errors.New(subscription.USER_NOT_FOUND + nickname)
+	}
+
+	f, err := u.forumUcase.GetForum(slug)
+	if err != nil {
+		return nil, errors.New(subscription.FORUM_NOT_FOUND + slug)
+	}
+
+	return &models.Subscription{Nickname: us.Nickname, ForumID: f.ID, Forum: f.Slug}, nil
+}
+
+func (u *SubscriptionUcase) unsubscribe(sub *models.Subscription) error {
+	removed, err := u.repository.Unsubscribe(sub)
+	if err != nil {
+		return errors.Wrap(err, "repository.Unsubscribe()")
+	}
+	if !removed {
+		return errors.New(subscription.NOT_FOUND)
+	}
+	return nil
+}
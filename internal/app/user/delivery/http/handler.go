@@ -24,6 +24,8 @@ func NewUserHandler(m *mux.Router, u user.Usecase, sessionStore sessions.Store)
 	}
 
 	m.HandleFunc("/", handler.MainHandler)
+	m.HandleFunc("/api/user/login", handler.Login).Methods(http.MethodPost)
+	m.HandleFunc("/api/user/logout", handler.Logout).Methods(http.MethodPost)
 	m.HandleFunc("/api/user/{nickname}/create", handler.CreateUser).Methods(http.MethodPost)
 	m.HandleFunc("/api/user/{nickname}/profile", handler.GetUser).Methods(http.MethodGet)
 	m.HandleFunc("/api/user/{nickname}/profile", handler.EditUser).Methods(http.MethodPost)
@@ -43,9 +45,12 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	newUser := new(models.User)
+	input := new(struct {
+		models.User
+		Password	string	`json:"password"`
+	})
 	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(newUser)
+	err := decoder.Decode(input)
 	if err != nil {
 		err = errors.Wrapf(err, "UserHandler.CreateUser<-Decode()")
 		general.Error(w, r, http.StatusBadRequest, err)
@@ -54,16 +59,68 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 	vars := mux.Vars(r)
 	name := vars["nickname"]
+	newUser := &input.User
 	newUser.Nickname = name
 
-	users, err := h.usecase.Create(newUser)
-	if err != nil {
+	users, err := h.usecase.Create(newUser, input.Password)
+	if err != nil && strings.Contains(err.Error(), user.WRONG_DIGEST) {
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
 		general.Respond(w, r, http.StatusConflict, &users)
 		return
 	}
+
+	if err := general.Login(w, r, h.sessionStore, newUser); err != nil {
+		general.Error(w, r, http.StatusInternalServerError, errors.Wrap(err, "UserHandler.CreateUser<-Login()"))
+		return
+	}
 	general.Respond(w, r, http.StatusCreated, newUser)
 }
 
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			err = errors.Wrapf(err, "UserHandler.Login<-r.Body.Close()")
+			general.Error(w, r, http.StatusInternalServerError, err)
+		}
+	}()
+
+	input := new(struct {
+		Nickname	string	`json:"nickname"`
+		Password	string	`json:"password"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		err = errors.Wrapf(err, "UserHandler.Login<-Decode()")
+		general.Error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	u, err := h.usecase.Login(input.Nickname, input.Password)
+	if err != nil {
+		general.Error(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := general.Login(w, r, h.sessionStore, u); err != nil {
+		general.Error(w, r, http.StatusInternalServerError, errors.Wrap(err, "UserHandler.Login<-Login()"))
+		return
+	}
+	general.Respond(w, r, http.StatusOK, u)
+}
+
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := general.Logout(w, r, h.sessionStore); err != nil {
+		general.Error(w, r, http.StatusInternalServerError, errors.Wrap(err, "UserHandler.Logout<-Logout()"))
+		return
+	}
+	general.Respond(w, r, http.StatusNoContent, nil)
+}
+
 func (h * Handler) GetUser(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -108,6 +165,8 @@ func (h * Handler) EditUser(w http.ResponseWriter, r *http.Request) {
 		} else if strings.Contains(err.Error(), user.NICKNAME_CONFLICT) ||
 			strings.Contains(err.Error(), user.EMAIL_CONFLICT) {
 			general.Error(w,r, http.StatusConflict, err)
+		} else if strings.Contains(err.Error(), user.WRONG_DIGEST) {
+			general.Error(w,r, http.StatusBadRequest, err)
 		} else {
 			general.Error(w,r, http.StatusInternalServerError, err)
 		}
@@ -3,8 +3,13 @@ package user
 import "github.com/efimovad/Forums.git/internal/models"
 
 type Repository interface {
-	Create(user *models.User) error
+	// Create stores the user with the password hash, "" for users that
+	// can't log in.
+	Create(user *models.User, password string) error
 	FindByEmail(email string) (*models.User, error)
 	FindByName(nickname string) (*models.User, error)
+	// FindPassword returns the password hash of the user. It is kept out of
+	// models.User so it never reaches a cache or a response.
+	FindPassword(nickname string) (string, error)
 	Edit(user *models.User) error
 }
@@ -14,20 +14,22 @@ func NewUserRepository(db *sql.DB) user.Repository {
 	return &Repository{db}
 }
 
-func (r *Repository) Create(user *models.User) error {
+func (r *Repository) Create(user *models.User, password string) error {
 	return r.db.QueryRow(
-		"INSERT INTO users (email, about, fullname, nickname) VALUES ($1, $2, $3, $4) RETURNING id",
+		"INSERT INTO users (email, about, fullname, nickname, digest, password) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
 		user.Email,
 		user.About,
 		user.FullName,
 		user.Nickname,
+		user.Digest,
+		password,
 	).Scan(&user.ID)
 }
 
 func (r *Repository) FindByEmail(email string) (*models.User, error) {
 	u := new(models.User)
 	if err := r.db.QueryRow(
-		"SELECT id, email, about, fullname, nickname FROM users WHERE LOWER(email) = LOWER($1)",
+		"SELECT id, email, about, fullname, nickname, digest FROM users WHERE LOWER(email) = LOWER($1)",
 		email,
 	).Scan(
 		&u.ID,
@@ -35,6 +37,7 @@ func (r *Repository) FindByEmail(email string) (*models.User, error) {
 		&u.About,
 		&u.FullName,
 		&u.Nickname,
+		&u.Digest,
 	); err != nil {
 		return nil, err
 	}
@@ -44,7 +47,7 @@ func (r *Repository) FindByEmail(email string) (*models.User, error) {
 func (r *Repository) FindByName(nickname string) (*models.User, error) {
 	u := new(models.User)
 	if err := r.db.QueryRow(
-		"SELECT id, email, about, fullname, nickname FROM users WHERE LOWER(nickname) = LOWER($1)",
+		"SELECT id, email, about, fullname, nickname, digest FROM users WHERE LOWER(nickname) = LOWER($1)",
 		nickname,
 	).Scan(
 		&u.ID,
@@ -52,18 +55,29 @@ func (r *Repository) FindByName(nickname string) (*models.User, error) {
 		&u.About,
 		&u.FullName,
 		&u.Nickname,
+		&u.Digest,
 	); err != nil {
 		return nil, err
 	}
 	return u, nil
 }
 
+func (r *Repository) FindPassword(nickname string) (string, error) {
+	var password string
+	err := r.db.QueryRow(
+		"SELECT COALESCE(password, '') FROM users WHERE LOWER(nickname) = LOWER($1)",
+		nickname,
+	).Scan(&password)
+	return password, err
+}
+
 func (r *Repository) Edit(user *models.User) error {
-	return r.db.QueryRow("UPDATE users SET email = $1, about = $2, fullname = $3 "+
-		"WHERE nickname = $4 RETURNING id",
+	return r.db.QueryRow("UPDATE users SET email = $1, about = $2, fullname = $3, digest = $4 "+
+		"WHERE nickname = $5 RETURNING id",
 		user.Email,
 		user.About,
 		user.FullName,
+		user.Digest,
 		user.Nickname,
 	).Scan(&user.ID)
 }
\ No newline at end of file
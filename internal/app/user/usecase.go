@@ -6,10 +6,17 @@ const (
 	NOT_FOUND_ERR = "Can't find user with nickname "
 	NICKNAME_CONFLICT = "Data conflict by nickname "
 	EMAIL_CONFLICT = "Data conflict by email "
+	WRONG_DIGEST = "Unknown digest frequency: "
+	WRONG_CREDENTIALS = "Wrong nickname or password"
+
+	DIGEST_NONE = "none"
+	DIGEST_DAILY = "daily"
+	DIGEST_WEEKLY = "weekly"
 )
 
 type Usecase interface {
-	Create(user *models.User) ([]*models.User, error)
+	Create(user *models.User, password string) ([]*models.User, error)
 	FindByName(nickname string) (*models.User, error)
+	Login(nickname string, password string) (*models.User, error)
 	Edit(name string, user *models.User) error
 }
@@ -0,0 +1,76 @@
+package user_ucase
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+const (
+	passwordScheme = "pbkdf2-sha256"
+	passwordIterations = 100000
+	saltSize = 16
+)
+
+// hashPassword returns "pbkdf2-sha256$<iterations>$<salt>$<key>" with salt
+// and key hex encoded.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := pbkdf2([]byte(password), salt, passwordIterations)
+	return passwordScheme + "$" + strconv.Itoa(passwordIterations) + "$" +
+		hex.EncodeToString(salt) + "$" + hex.EncodeToString(key), nil
+}
+
+func checkPassword(password string, hash string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+
+	salt, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+
+	key, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(pbkdf2([]byte(password), salt, iterations), key)
+}
+
+// pbkdf2 derives a single block, one SHA-256 output long, as in RFC 8018.
+func pbkdf2(password []byte, salt []byte, iterations int) []byte {
+	prf := hmac.New(sha256.New, password)
+
+	block := make([]byte, 4)
+	binary.BigEndian.PutUint32(block, 1)
+	prf.Write(salt)
+	prf.Write(block)
+	u := prf.Sum(nil)
+
+	key := make([]byte, len(u))
+	copy(key, u)
+	for i := 1; i < iterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
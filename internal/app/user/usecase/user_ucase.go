@@ -17,14 +17,23 @@ func NewUserUsecase(r user.Repository) user.Usecase {
 	}
 }
 
-func (u * UserUcase) Create(user *models.User) ([]*models.User, error) {
+// Create registers the user. A user created without a password can't log
+// in later.
+func (u * UserUcase) Create(newUser *models.User, password string) ([]*models.User, error) {
+	if newUser.Digest == "" {
+		newUser.Digest = user.DIGEST_NONE
+	}
+	if !validDigest(newUser.Digest) {
+		return nil, errors.New(user.WRONG_DIGEST + newUser.Digest)
+	}
+
 	var users []*models.User
-	user1, err := u.repository.FindByEmail(user.Email)
+	user1, err := u.repository.FindByEmail(newUser.Email)
 	if err == nil {
 		users = append(users, user1)
 	}
 
-	user2, err := u.repository.FindByName(user.Nickname)
+	user2, err := u.repository.FindByName(newUser.Nickname)
 	if  err == nil && (user1 == nil || user1.Email != user2.Email) {
 		users = append(users, user2)
 	}
@@ -33,13 +42,36 @@ func (u * UserUcase) Create(user *models.User) ([]*models.User, error) {
 		return users, errors.New("user already exist")
 	}
 
-	if err := u.repository.Create(user); err != nil {
+	var hash string
+	if password != "" {
+		var err error
+		if hash, err = hashPassword(password); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.repository.Create(newUser, hash); err != nil {
 		return nil, errors.Wrap(err, "repository.Create()")
 	}
 
 	return nil, nil
 }
 
+// Login checks the password of the user. Unknown users, users without a
+// password and wrong passwords all get the same error.
+func (u *UserUcase) Login(nickname string, password string) (*models.User, error) {
+	hash, err := u.repository.FindPassword(nickname)
+	if err != nil || hash == "" || !checkPassword(password, hash) {
+		return nil, errors.New(user.WRONG_CREDENTIALS)
+	}
+
+	found, err := u.repository.FindByName(nickname)
+	if err != nil {
+		return nil, errors.New(user.WRONG_CREDENTIALS)
+	}
+	return found, nil
+}
+
 func (u *UserUcase) FindByName(nickname string) (*models.User, error) {
 	myUser, err := u.repository.FindByName(nickname)
 	if err != nil {
@@ -75,9 +107,20 @@ func (u *UserUcase) Edit(name string, user2edit *models.User) error {
 		user2edit.FullName = currUser.FullName
 	}
 
+	if user2edit.Digest == "" {
+		user2edit.Digest = currUser.Digest
+	}
+	if !validDigest(user2edit.Digest) {
+		return errors.New(user.WRONG_DIGEST + user2edit.Digest)
+	}
+
 	if err := u.repository.Edit(user2edit); err != nil {
 		return err
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func validDigest(digest string) bool {
+	return digest == user.DIGEST_NONE || digest == user.DIGEST_DAILY || digest == user.DIGEST_WEEKLY
+}
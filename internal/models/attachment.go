@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Attachment struct {
+	ID			int64		`json:"id"`
+	Post		int64		`json:"post"`
+	Filename	string		`json:"filename"`
+	MimeType	string		`json:"mime_type"`
+	Size		int64		`json:"size"`
+	Key			string		`json:"-"`
+	URL			string		`json:"url"`
+	Created		time.Time	`json:"created"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ThreadAudit records a moderator action on a thread. Target is the other
+// thread of a merge or split, Forum the destination of a move.
+type ThreadAudit struct {
+	ID			int64		`json:"id"`
+	Action		string		`json:"action"`
+	Moderator	string		`json:"moderator"`
+	Thread		int64		`json:"thread"`
+	Target		int64		`json:"target,omitempty"`
+	Forum		string		`json:"forum,omitempty"`
+	Posts		int64		`json:"posts"`
+	Created		time.Time	`json:"created"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Bookmark struct {
+	ID			int64		`json:"id"`
+	Nickname	string		`json:"nickname"`
+	PostID		int64		`json:"post_id,omitempty"`
+	ThreadID	int64		`json:"thread_id,omitempty"`
+	Note		string		`json:"note,omitempty"`
+	Created		time.Time	`json:"created"`
+	Post		*Post		`json:"post,omitempty"`
+	Thread		*Thread		`json:"thread,omitempty"`
+	Deleted		bool		`json:"deleted,omitempty"`
+}
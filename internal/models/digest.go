@@ -0,0 +1,8 @@
+package models
+
+type DigestThread struct {
+	Thread	int64	`json:"thread"`
+	Forum	string	`json:"forum"`
+	Title	string	`json:"title"`
+	Posts	int64	`json:"posts"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Draft struct {
+	ID			int64		`json:"id"`
+	Nickname	string		`json:"nickname"`
+	Thread		int64		`json:"thread"`
+	ThreadSlug	string		`json:"thread_slug,omitempty"`
+	ThreadTitle	string		`json:"thread_title,omitempty"`
+	Parent		int64		`json:"parent,omitempty"`
+	Message		string		`json:"message"`
+	Created		time.Time	`json:"created"`
+	Updated		time.Time	`json:"updated"`
+}
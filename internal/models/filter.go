@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// FilterSettings configure the content filters of a forum. Actions maps a
+// filter name to what happens when it matches; filters without an action
+// and zero limits are disabled.
+type FilterSettings struct {
+	BannedWords		[]string			`json:"banned_words"`
+	BannedPatterns	[]string			`json:"banned_patterns"`
+	MaxLinks		int					`json:"max_links"`
+	DuplicateWindow	int64				`json:"duplicate_window"`
+	NewAccountAge	int64				`json:"new_account_age"`
+	NewAccountPosts	int					`json:"new_account_posts"`
+	Actions			map[string]string	`json:"actions"`
+}
+
+type FilterVerdict struct {
+	ID		int64	`json:"id,omitempty"`
+	Action	string	`json:"action"`
+	Filter	string	`json:"filter"`
+	Reason	string	`json:"reason"`
+}
+
+// ModerationItem is a thread or post a filter held back or flagged. Held
+// items are published when a moderator approves them; flagged ones are
+// already published and Published holds their id.
+type ModerationItem struct {
+	ID			int64		`json:"id"`
+	Kind		string		`json:"kind"`
+	Forum		string		`json:"forum"`
+	Thread		int64		`json:"thread,omitempty"`
+	Parent		int64		`json:"parent,omitempty"`
+	Author		string		`json:"author"`
+	Title		string		`json:"title,omitempty"`
+	Slug		string		`json:"slug,omitempty"`
+	Message		string		`json:"message"`
+	Tags		[]string	`json:"tags,omitempty"`
+	Quotes		[]int64		`json:"quotes,omitempty"`
+	Action		string		`json:"action"`
+	Filter		string		`json:"filter"`
+	Reason		string		`json:"reason"`
+	Status		string		`json:"status"`
+	Published	int64		`json:"published,omitempty"`
+	Moderator	string		`json:"moderator,omitempty"`
+	Created		time.Time	`json:"created"`
+}
@@ -9,6 +9,12 @@ type Forum struct {
 	User	string	`json:"user,omitempty"`
 	Posts	int64	`json:"posts,omitempty"`
 	Threads	int64	`json:"threads,omitempty"`
+	Parent	string	`json:"parent,omitempty"`
+	ParentID	int64	`json:"-"`
+	Children	[]*Forum	`json:"children,omitempty"`
+	TotalPosts	int64	`json:"total_posts,omitempty"`
+	TotalThreads	int64	`json:"total_threads,omitempty"`
+	Modified	time.Time	`json:"-"`
 }
 
 type Thread struct {
@@ -17,9 +23,20 @@ type Thread struct {
 	Author	string		`json:"author,omitempty"`
 	Created	time.Time	`json:"created"`
 	Message	string		`json:"message,omitempty"`
+	MessageHTML	string	`json:"message_html,omitempty"`
 	Title	string		`json:"title,omitempty"`
 	Slug	string		`json:"slug,omitempty"`
 	Votes	int64		`json:"votes,omitempty"`
+	Upvotes	int64		`json:"upvotes,omitempty"`
+	Downvotes	int64	`json:"downvotes,omitempty"`
+	Version	int64		`json:"version,omitempty"`
+	Reactions	map[string]int64	`json:"reactions,omitempty"`
+	Unread	*int64		`json:"unread,omitempty"`
+	MovedTo	int64		`json:"moved_to,omitempty"`
+	Tags	[]string	`json:"tags,omitempty"`
+	Poll	*Poll		`json:"poll,omitempty"`
+	Moderation	*FilterVerdict	`json:"moderation,omitempty"`
+	Modified	time.Time	`json:"-"`
 }
 
 type Post struct {
@@ -29,8 +46,29 @@ type Post struct {
 	Forum		string		`json:"forum,omitempty"`
 	IsEdited	bool		`json:"isEdited,omitempty"`
 	Message		string		`json:"message,omitempty"`
+	MessageHTML	string		`json:"message_html,omitempty"`
 	Parent		int64		`json:"parent"`
 	Thread		int64		`json:"thread,omitempty"`
 	Slug		string		`json:"slug,omitempty"`
 	Path		[]int64		`json:"-"`
+	Votes		int64		`json:"votes,omitempty"`
+	Version		int64		`json:"version,omitempty"`
+	Reactions	map[string]int64	`json:"reactions,omitempty"`
+	Quotes		[]*Quote	`json:"quotes,omitempty"`
+	Attachments	[]*Attachment	`json:"attachments,omitempty"`
+	Moderation	*FilterVerdict	`json:"moderation,omitempty"`
+	Modified	time.Time	`json:"-"`
+}
+
+type TagCount struct {
+	Tag		string	`json:"tag"`
+	Threads	int64	`json:"threads"`
+}
+
+// Quote references a post quoted by another one, possibly from another thread.
+type Quote struct {
+	Post	int64	`json:"post"`
+	Author	string	`json:"author,omitempty"`
+	Thread	int64	`json:"thread,omitempty"`
+	Link	string	`json:"link,omitempty"`
 }
\ No newline at end of file
@@ -5,4 +5,9 @@ type ListParameters struct {
 	Since	string	`json:"since"`
 	Desc	bool	`json:"desc"`
 	Sort	string	`json:"sort"`
+	User	string	`json:"user"`
+	Tags	[]string	`json:"tags"`
+	TagMode	string	`json:"tag_mode"`
+	Subforums	bool	`json:"subforums"`
+	Forums	[]string	`json:"forums"`
 }
\ No newline at end of file
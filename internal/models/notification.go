@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID			int64		`json:"id"`
+	Nickname	string		`json:"nickname"`
+	Kind		string		`json:"kind"`
+	Actor		string		`json:"actor,omitempty"`
+	Thread		int64		`json:"thread,omitempty"`
+	Post		int64		`json:"post,omitempty"`
+	Created		time.Time	`json:"created"`
+	Read		bool		`json:"read"`
+}
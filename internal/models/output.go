@@ -12,4 +12,14 @@ type Combine struct {
 	Forum *Forum `json:"forum"`
 	Thread *Thread `json:"thread"`
 	Author *User `json:"author"`
-}
\ No newline at end of file
+	Quotes []*Post `json:"quotes,omitempty"`
+	QuotedBy []*Post `json:"quoted_by,omitempty"`
+}
+
+type CounterDrift struct {
+	Forum	string	`json:"forum"`
+	Thread	int64	`json:"thread,omitempty"`
+	Counter	string	`json:"counter"`
+	Stored	int64	`json:"stored"`
+	Actual	int64	`json:"actual"`
+}
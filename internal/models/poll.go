@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type Poll struct {
+	ID			int64			`json:"id"`
+	Thread		int64			`json:"thread"`
+	Question	string			`json:"question"`
+	Multiple	bool			`json:"multiple"`
+	Anonymous	bool			`json:"anonymous"`
+	Closes		*time.Time		`json:"closes,omitempty"`
+	Closed		bool			`json:"closed"`
+	Voters		int64			`json:"voters"`
+	Options		[]*PollOption	`json:"options"`
+	Created		time.Time		`json:"created"`
+}
+
+// PollOption lists who voted for it unless the poll is anonymous.
+type PollOption struct {
+	ID		int64		`json:"id"`
+	Text	string		`json:"text"`
+	Votes	int64		`json:"votes"`
+	Voters	[]string	`json:"voters,omitempty"`
+}
+
+type PollVote struct {
+	Nickname	string	`json:"nickname"`
+	Options		[]int64	`json:"options"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type Reaction struct {
+	ID			int64		`json:"-"`
+	Nickname	string		`json:"nickname"`
+	Reaction	string		`json:"reaction"`
+	Post		int64		`json:"post,omitempty"`
+	Thread		int64		`json:"thread,omitempty"`
+	Created		time.Time	`json:"created"`
+}
@@ -0,0 +1,8 @@
+package models
+
+type ReadMarker struct {
+	Nickname	string	`json:"nickname"`
+	Thread		int64	`json:"thread"`
+	Post		int64	`json:"post"`
+	Unread		int64	`json:"unread"`
+}
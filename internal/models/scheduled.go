@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Scheduled is a thread or post waiting for its publish time. Published
+// holds the id of the created thread or post. PreviousClaim is set when an
+// item is claimed again after an earlier attempt ran out of time.
+type Scheduled struct {
+	ID			int64		`json:"id"`
+	Kind		string		`json:"kind"`
+	Author		string		`json:"author"`
+	Forum		string		`json:"forum,omitempty"`
+	Thread		int64		`json:"thread,omitempty"`
+	Parent		int64		`json:"parent,omitempty"`
+	Title		string		`json:"title,omitempty"`
+	Slug		string		`json:"slug,omitempty"`
+	Message		string		`json:"message"`
+	Tags		[]string	`json:"tags,omitempty"`
+	PublishAt	time.Time	`json:"publish_at"`
+	Status		string		`json:"status"`
+	Error		string		`json:"error,omitempty"`
+	Published	int64		`json:"published,omitempty"`
+	Created		time.Time	`json:"created"`
+
+	PreviousClaim	*time.Time	`json:"-"`
+}
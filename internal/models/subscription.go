@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type Subscription struct {
+	ID			int64		`json:"-"`
+	Nickname	string		`json:"nickname"`
+	Thread		int64		`json:"thread,omitempty"`
+	ForumID		int64		`json:"-"`
+	Forum		string		`json:"forum,omitempty"`
+	Created		time.Time	`json:"created"`
+}
@@ -1,9 +1,13 @@
 package models
 
+import "time"
+
 type User struct {
-	ID			int64	`json:"-"`
-	About 		string	`json:"about"`
-	Email 		string	`json:"email"`
-	FullName	string	`json:"fullname"`
-	Nickname 	string	`json:"nickname"`
+	ID			int64		`json:"-"`
+	About 		string		`json:"about"`
+	Email 		string		`json:"email"`
+	FullName	string		`json:"fullname"`
+	Nickname 	string		`json:"nickname"`
+	Digest		string		`json:"digest,omitempty"`
+	DigestSent	time.Time	`json:"-"`
 }
@@ -5,4 +5,5 @@ type Vote struct {
 	Nickname	string	`json:"nickname"`
 	Voice		int64	`json:"voice"`
 	Thread		string	`json:"thread"`
+	Post		int64	`json:"post,omitempty"`
 }
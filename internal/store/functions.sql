@@ -1,5 +1,27 @@
 DROP TRIGGER IF EXISTS on_post_insert ON posts;
 DROP TRIGGER IF EXISTS on_thread_insert ON threads;
+DROP TRIGGER IF EXISTS on_thread_count ON threads;
+
+ALTER TABLE forums ADD COLUMN IF NOT EXISTS threads int DEFAULT 0;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest varchar DEFAULT 'none';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_sent timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password varchar DEFAULT '';
+
+DROP TRIGGER IF EXISTS on_forum_modify ON forums;
+DROP TRIGGER IF EXISTS on_thread_modify ON threads;
+DROP TRIGGER IF EXISTS on_post_modify ON posts;
+
+ALTER TABLE forums ADD COLUMN IF NOT EXISTS modified timestamptz DEFAULT now();
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS modified timestamptz DEFAULT now();
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS modified timestamptz DEFAULT now();
+
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS version integer DEFAULT 1;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS version integer DEFAULT 1;
+
+DROP TRIGGER IF EXISTS on_post_vote ON post_votes;
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS votes integer DEFAULT 0;
 
 CREATE TABLE IF NOT EXISTS forum_users (
     user_id BIGINT REFERENCES users(id),
@@ -8,8 +30,18 @@ CREATE TABLE IF NOT EXISTS forum_users (
 
 DROP TRIGGER IF EXISTS on_vote_insert ON votes;
 DROP TRIGGER IF EXISTS on_vote_update ON votes;
+DROP TRIGGER IF EXISTS on_vote_delete ON votes;
+
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS upvotes integer DEFAULT 0;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS downvotes integer DEFAULT 0;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS moved_to bigint;
+ALTER TABLE forums ADD COLUMN IF NOT EXISTS parent bigint REFERENCES forums(id);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS created timestamptz;
+ALTER TABLE users ALTER COLUMN created SET DEFAULT now();
+ALTER TABLE scheduled ADD COLUMN IF NOT EXISTS claimed timestamptz;
 
 CREATE UNIQUE INDEX IF NOT EXISTS idx_forums_slug ON forums (LOWER(slug));
+CREATE INDEX IF NOT EXISTS idx_forums_parent ON forums (parent);
 CREATE INDEX IF NOT EXISTS idx_forums_user ON forums ("user");
 
 CREATE INDEX IF NOT EXISTS idx_threads_slug ON threads (LOWER(slug));
@@ -23,16 +55,59 @@ CREATE INDEX IF NOT EXISTS idx_posts_thread ON posts (thread);
 CREATE INDEX IF NOT EXISTS idx_posts_forum ON posts (forum);
 CREATE INDEX IF NOT EXISTS idx_posts_parent ON posts (parent);
 CREATE INDEX IF NOT EXISTS idx_posts_thread_id ON posts (thread, id);
+CREATE INDEX IF NOT EXISTS idx_posts_author_created ON posts (LOWER(author), created);
 
 CREATE INDEX IF NOT EXISTS idx_votes_author ON votes (LOWER(nickname));
 CREATE UNIQUE INDEX IF NOT EXISTS idx_votes_nickname_thread_unique ON votes (LOWER(nickname), thread);
 
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_votes_nickname_post_unique ON post_votes (LOWER(nickname), post);
+CREATE INDEX IF NOT EXISTS idx_posts_thread_votes ON posts (thread, votes);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_post_unique ON reactions (LOWER(nickname), reaction, post) WHERE post IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_thread_unique ON reactions (LOWER(nickname), reaction, thread) WHERE thread IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_nickname ON notifications (LOWER(nickname), id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_thread_unique ON subscriptions (LOWER(nickname), thread) WHERE thread IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_forum_unique ON subscriptions (LOWER(nickname), forum_id) WHERE forum_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_subscriptions_thread ON subscriptions (thread);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_forum ON subscriptions (forum_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_thread_reads_unique ON thread_reads (LOWER(nickname), thread);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_post ON attachments (post);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_drafts_nickname_thread_unique ON drafts (LOWER(nickname), thread);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_due ON scheduled (publish_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_scheduled_author ON scheduled (LOWER(author), id);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_queue_forum ON moderation_queue (LOWER(forum), id) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_poll_options_poll ON poll_options (poll);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poll_votes_nickname_option_unique ON poll_votes (LOWER(nickname), option);
+CREATE INDEX IF NOT EXISTS idx_poll_votes_poll ON poll_votes (poll);
+
+CREATE INDEX IF NOT EXISTS idx_thread_tags_tag ON thread_tags (tag);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_slug_aliases_slug ON slug_aliases (kind, LOWER(slug));
+
+CREATE INDEX IF NOT EXISTS idx_thread_audit_thread ON thread_audit (thread);
+CREATE INDEX IF NOT EXISTS idx_thread_audit_target ON thread_audit (target);
+
+CREATE INDEX IF NOT EXISTS idx_post_quotes_quoted ON post_quotes (quoted);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_post_unique ON bookmarks (LOWER(nickname), post) WHERE post IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_thread_unique ON bookmarks (LOWER(nickname), thread) WHERE thread IS NOT NULL;
+
 CREATE OR REPLACE FUNCTION fn_update_thread_votes_ins()
     RETURNS TRIGGER AS '
     BEGIN
         UPDATE threads
         SET
-            votes = votes + NEW.vote
+            votes = votes + NEW.vote,
+            upvotes = upvotes + CASE WHEN NEW.vote > 0 THEN 1 ELSE 0 END,
+            downvotes = downvotes + CASE WHEN NEW.vote < 0 THEN 1 ELSE 0 END
         WHERE id = NEW.thread;
         RETURN NULL;
     END;
@@ -51,9 +126,9 @@ CREATE OR REPLACE FUNCTION fn_update_thread_votes_upd()
         END IF;
         UPDATE threads
         SET
-            votes = votes + CASE WHEN NEW.vote = -1
-                                     THEN -2
-                                 ELSE 2 END
+            votes = votes + NEW.vote - OLD.vote,
+            upvotes = upvotes + CASE WHEN NEW.vote > 0 THEN 1 ELSE 0 END - CASE WHEN OLD.vote > 0 THEN 1 ELSE 0 END,
+            downvotes = downvotes + CASE WHEN NEW.vote < 0 THEN 1 ELSE 0 END - CASE WHEN OLD.vote < 0 THEN 1 ELSE 0 END
         WHERE id = NEW.thread;
         RETURN NULL;
     END;
@@ -63,6 +138,23 @@ CREATE TRIGGER on_vote_update
     AFTER UPDATE ON votes
     FOR EACH ROW EXECUTE PROCEDURE fn_update_thread_votes_upd();
 
+CREATE OR REPLACE FUNCTION fn_update_thread_votes_del()
+    RETURNS TRIGGER AS '
+    BEGIN
+        UPDATE threads
+        SET
+            votes = votes - OLD.vote,
+            upvotes = upvotes - CASE WHEN OLD.vote > 0 THEN 1 ELSE 0 END,
+            downvotes = downvotes - CASE WHEN OLD.vote < 0 THEN 1 ELSE 0 END
+        WHERE id = OLD.thread;
+        RETURN NULL;
+    END;
+' LANGUAGE plpgsql;
+
+CREATE TRIGGER on_vote_delete
+    AFTER DELETE ON votes
+    FOR EACH ROW EXECUTE PROCEDURE fn_update_thread_votes_del();
+
 CREATE OR REPLACE FUNCTION forum_users_update()
     RETURNS TRIGGER AS '
     BEGIN
@@ -78,4 +170,72 @@ CREATE TRIGGER on_post_insert
 
 CREATE TRIGGER on_thread_insert
     AFTER INSERT ON threads
-    FOR EACH ROW EXECUTE PROCEDURE forum_users_update();
\ No newline at end of file
+    FOR EACH ROW EXECUTE PROCEDURE forum_users_update();
+
+CREATE OR REPLACE FUNCTION fn_update_forum_threads()
+    RETURNS TRIGGER AS '
+    BEGIN
+        IF TG_OP = ''UPDATE'' AND LOWER(OLD.forum) = LOWER(NEW.forum) AND
+           (OLD.moved_to IS NULL) = (NEW.moved_to IS NULL)
+        THEN
+            RETURN NULL;
+        END IF;
+        IF (TG_OP = ''INSERT'' OR TG_OP = ''UPDATE'') AND NEW.moved_to IS NULL
+        THEN
+            UPDATE forums SET threads = threads + 1 WHERE LOWER(slug) = LOWER(NEW.forum);
+        END IF;
+        IF (TG_OP = ''DELETE'' OR TG_OP = ''UPDATE'') AND OLD.moved_to IS NULL
+        THEN
+            UPDATE forums SET threads = threads - 1 WHERE LOWER(slug) = LOWER(OLD.forum);
+        END IF;
+        RETURN NULL;
+    END;
+' LANGUAGE plpgsql;
+
+CREATE TRIGGER on_thread_count
+    AFTER INSERT OR DELETE OR UPDATE OF forum, moved_to ON threads
+    FOR EACH ROW EXECUTE PROCEDURE fn_update_forum_threads();
+
+CREATE OR REPLACE FUNCTION fn_touch_modified()
+    RETURNS TRIGGER AS '
+    BEGIN
+        NEW.modified = now();
+        RETURN NEW;
+    END;
+' LANGUAGE plpgsql;
+
+CREATE TRIGGER on_forum_modify
+    BEFORE UPDATE ON forums
+    FOR EACH ROW EXECUTE PROCEDURE fn_touch_modified();
+
+CREATE TRIGGER on_thread_modify
+    BEFORE UPDATE ON threads
+    FOR EACH ROW EXECUTE PROCEDURE fn_touch_modified();
+
+CREATE TRIGGER on_post_modify
+    BEFORE UPDATE ON posts
+    FOR EACH ROW EXECUTE PROCEDURE fn_touch_modified();
+
+CREATE OR REPLACE FUNCTION fn_update_post_votes()
+    RETURNS TRIGGER AS '
+    BEGIN
+        IF TG_OP = ''INSERT''
+        THEN
+            UPDATE posts SET votes = votes + NEW.vote WHERE id = NEW.post;
+        ELSIF TG_OP = ''UPDATE''
+        THEN
+            IF OLD.vote = NEW.vote
+            THEN
+                RETURN NULL;
+            END IF;
+            UPDATE posts SET votes = votes + NEW.vote - OLD.vote WHERE id = NEW.post;
+        ELSE
+            UPDATE posts SET votes = votes - OLD.vote WHERE id = OLD.post;
+        END IF;
+        RETURN NULL;
+    END;
+' LANGUAGE plpgsql;
+
+CREATE TRIGGER on_post_vote
+    AFTER INSERT OR UPDATE OR DELETE ON post_votes
+    FOR EACH ROW EXECUTE PROCEDURE fn_update_post_votes();
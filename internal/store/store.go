@@ -7,13 +7,27 @@ import (
 )
 
 func New(dbURL string) (*sql.DB, error) {
+	db, err := Open(dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := createTables(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Open connects to the database without touching its schema.
+func Open(dbURL string) (*sql.DB, error) {
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		return nil, err
 	}
 
 	db.SetMaxOpenConns(20)
-	if err := createTables(db); err != nil {
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 	return db, nil
@@ -36,7 +50,8 @@ func createTables(db *sql.DB) error{
 		slug varchar unique not null,
 		title varchar,
 		"user" varchar not null,
-		posts    int default 0 
+		posts    int default 0,
+		threads  int default 0
 	);`
 	if _, err := db.Exec(forumQuery); err != nil {
 		return err
@@ -100,6 +115,230 @@ func createTables(db *sql.DB) error{
 		return err
 	}
 
+	postVoteQuery := `CREATE TABLE IF NOT EXISTS post_votes (
+    	id bigserial not null primary key,
+		nickname varchar,
+		vote integer,
+		post bigint references posts(id)
+	);`
+	if _, err := db.Exec(postVoteQuery); err != nil {
+		return err
+	}
+
+	reactionQuery := `CREATE TABLE IF NOT EXISTS reactions (
+    	id bigserial not null primary key,
+		nickname varchar not null,
+		reaction varchar not null,
+		post bigint references posts(id),
+		thread integer references threads(id),
+		created timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(reactionQuery); err != nil {
+		return err
+	}
+
+	notificationQuery := `CREATE TABLE IF NOT EXISTS notifications (
+    	id bigserial not null primary key,
+		nickname varchar not null,
+		kind varchar not null,
+		actor varchar,
+		thread integer,
+		post bigint,
+		created timestamptz DEFAULT now(),
+		is_read boolean DEFAULT FALSE
+	);`
+	if _, err := db.Exec(notificationQuery); err != nil {
+		return err
+	}
+
+	subscriptionQuery := `CREATE TABLE IF NOT EXISTS subscriptions (
+    	id bigserial not null primary key,
+		nickname varchar not null,
+		thread integer references threads(id),
+		forum_id bigint references forums(id),
+		created timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(subscriptionQuery); err != nil {
+		return err
+	}
+
+	readQuery := `CREATE TABLE IF NOT EXISTS thread_reads (
+    	id bigserial not null primary key,
+		nickname varchar not null,
+		thread integer references threads(id),
+		last_post bigint DEFAULT 0
+	);`
+	if _, err := db.Exec(readQuery); err != nil {
+		return err
+	}
+
+	bookmarkQuery := `CREATE TABLE IF NOT EXISTS bookmarks (
+    	id bigserial not null primary key,
+		nickname varchar not null,
+		post bigint,
+		thread integer,
+		note varchar DEFAULT '',
+		created timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(bookmarkQuery); err != nil {
+		return err
+	}
+
+	quoteQuery := `CREATE TABLE IF NOT EXISTS post_quotes (
+		post bigint references posts(id),
+		quoted bigint references posts(id),
+		primary key (post, quoted)
+	);`
+	if _, err := db.Exec(quoteQuery); err != nil {
+		return err
+	}
+
+	auditQuery := `CREATE TABLE IF NOT EXISTS thread_audit (
+    	id bigserial not null primary key,
+		action varchar not null,
+		moderator varchar,
+		thread bigint,
+		target bigint,
+		forum varchar,
+		posts bigint DEFAULT 0,
+		created timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(auditQuery); err != nil {
+		return err
+	}
+
+	aliasQuery := `CREATE TABLE IF NOT EXISTS slug_aliases (
+    	id bigserial not null primary key,
+		kind varchar not null,
+		slug varchar not null,
+		target bigint not null,
+		created timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(aliasQuery); err != nil {
+		return err
+	}
+
+	tagQuery := `CREATE TABLE IF NOT EXISTS thread_tags (
+		thread bigint references threads(id),
+		tag varchar not null,
+		primary key (thread, tag)
+	);`
+	if _, err := db.Exec(tagQuery); err != nil {
+		return err
+	}
+
+	pollQuery := `CREATE TABLE IF NOT EXISTS polls (
+    	id bigserial not null primary key,
+		thread bigint unique references threads(id),
+		question varchar not null,
+		multiple boolean DEFAULT FALSE,
+		anonymous boolean DEFAULT FALSE,
+		closes timestamptz,
+		created timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(pollQuery); err != nil {
+		return err
+	}
+
+	pollOptionQuery := `CREATE TABLE IF NOT EXISTS poll_options (
+    	id bigserial not null primary key,
+		poll bigint references polls(id),
+		position integer not null,
+		text varchar not null
+	);`
+	if _, err := db.Exec(pollOptionQuery); err != nil {
+		return err
+	}
+
+	pollVoteQuery := `CREATE TABLE IF NOT EXISTS poll_votes (
+    	id bigserial not null primary key,
+		poll bigint references polls(id),
+		option bigint references poll_options(id),
+		nickname varchar not null
+	);`
+	if _, err := db.Exec(pollVoteQuery); err != nil {
+		return err
+	}
+
+	attachmentQuery := `CREATE TABLE IF NOT EXISTS attachments (
+    	id bigserial not null primary key,
+		post bigint references posts(id),
+		filename varchar not null,
+		mime varchar not null,
+		size bigint not null,
+		key varchar not null,
+		created timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(attachmentQuery); err != nil {
+		return err
+	}
+
+	draftQuery := `CREATE TABLE IF NOT EXISTS drafts (
+    	id bigserial not null primary key,
+		nickname varchar not null,
+		thread integer references threads(id),
+		parent bigint DEFAULT 0,
+		message varchar not null,
+		created timestamptz DEFAULT now(),
+		updated timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(draftQuery); err != nil {
+		return err
+	}
+
+	scheduledQuery := `CREATE TABLE IF NOT EXISTS scheduled (
+    	id bigserial not null primary key,
+		kind varchar not null,
+		author varchar not null,
+		forum varchar not null,
+		thread integer references threads(id),
+		parent bigint DEFAULT 0,
+		title varchar DEFAULT '',
+		slug varchar DEFAULT '',
+		message varchar not null,
+		tags varchar[],
+		publish_at timestamptz not null,
+		status varchar DEFAULT 'pending',
+		error varchar DEFAULT '',
+		published bigint DEFAULT 0,
+		created timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(scheduledQuery); err != nil {
+		return err
+	}
+
+	forumFilterQuery := `CREATE TABLE IF NOT EXISTS forum_filters (
+		forum bigint not null primary key references forums(id),
+		settings json not null
+	);`
+	if _, err := db.Exec(forumFilterQuery); err != nil {
+		return err
+	}
+
+	moderationQuery := `CREATE TABLE IF NOT EXISTS moderation_queue (
+    	id bigserial not null primary key,
+		kind varchar not null,
+		forum varchar not null,
+		thread integer references threads(id),
+		parent bigint DEFAULT 0,
+		author varchar not null,
+		title varchar DEFAULT '',
+		slug varchar DEFAULT '',
+		message varchar not null,
+		tags varchar[],
+		quotes bigint[],
+		action varchar not null,
+		filter varchar not null,
+		reason varchar DEFAULT '',
+		status varchar DEFAULT 'pending',
+		published bigint DEFAULT 0,
+		moderator varchar DEFAULT '',
+		created timestamptz DEFAULT now()
+	);`
+	if _, err := db.Exec(moderationQuery); err != nil {
+		return err
+	}
+
 	file, err := ioutil.ReadFile("./internal/store/functions.sql")
 	if err != nil {
 		return err